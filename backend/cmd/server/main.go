@@ -3,12 +3,16 @@ package main
 import (
 	"net/http"
 	"os"
-	"strconv"
+	"time"
 
 	"kanban-backend/internal/database"
 	"kanban-backend/internal/handlers"
+	"kanban-backend/internal/jobs"
 	"kanban-backend/internal/logger"
+	"kanban-backend/internal/mail"
 	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/oidc"
+	"kanban-backend/internal/search"
 	"kanban-backend/internal/websocket"
 
 	"github.com/gin-gonic/gin"
@@ -36,14 +40,57 @@ func main() {
 	rocketChatHub := websocket.NewRocketChatHub(database.GetDB())
 	go rocketChatHub.Run()
 
+	// Purge trashed boards, columns and tasks past their retention window
+	trashPurger := jobs.NewTrashPurger(database.GetDB(), jobs.TrashRetention(), time.Hour)
+	go trashPurger.Run()
+
+	// Announce tasks that are due soon or overdue
+	dueDateScheduler := jobs.NewDueDateScheduler(database.GetDB(), hub, jobs.DueSoonWindow(), time.Minute)
+	go dueDateScheduler.Run()
+
+	// Clone recurring tasks into their boards as they come due
+	recurringTaskScheduler := jobs.NewRecurringTaskScheduler(database.GetDB(), hub, handlers.LoadTaskResponse, time.Minute)
+	go recurringTaskScheduler.Run()
+
+	// Queue board events for outgoing webhooks and deliver them
+	webhookDispatcher := jobs.NewWebhookDispatcher(database.GetDB(), 5*time.Second)
+	hub.AddBoardEventListener(webhookDispatcher.Enqueue)
+	go webhookDispatcher.Run()
+
+	// Send email through SMTP, or to the log in development
+	mailer := mail.NewMailerFromEnv()
+
+	// Single sign-on through an OpenID Connect provider, when configured
+	var oidcProvider *oidc.Provider
+	if config, ok := oidc.ConfigFromEnv(); ok {
+		oidcProvider = oidc.NewProvider(config)
+	} else if len(oidc.EnforcedDomains()) > 0 {
+		logger.Log.Warn("SSO_ENFORCED_DOMAINS is set but OIDC_ISSUER is not; those domains cannot sign in")
+	}
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler()
-	boardHandler := handlers.NewBoardHandler(hub)
+	authHandler := handlers.NewAuthHandler(mailer)
+	boardHandler := handlers.NewBoardHandler(hub, mailer)
+	oidcHandler := handlers.NewOIDCHandler(oidcProvider)
 	taskHandler := handlers.NewTaskHandler(hub)
-    columnHandler := handlers.NewColumnHandler()
+	taskCommentHandler := handlers.NewTaskCommentHandler(hub)
+	labelHandler := handlers.NewLabelHandler(hub)
+	customFieldHandler := handlers.NewCustomFieldHandler(hub)
+	worklogHandler := handlers.NewWorklogHandler(hub)
+	recurringTaskHandler := handlers.NewRecurringTaskHandler(hub)
+	automationHandler := handlers.NewAutomationHandler(hub)
+	webhookHandler := handlers.NewWebhookHandler()
+    columnHandler := handlers.NewColumnHandler(hub)
 	chatHandler := handlers.NewChatHandler(hub)
 	privateMessageHandler := handlers.NewPrivateMessageHandler(hub)
 	rocketChatHandler := handlers.NewRocketChatHandler(database.GetDB())
+	boardTemplateHandler := handlers.NewBoardTemplateHandler()
+	searchHandler := handlers.NewSearchHandler(search.New(database.GetDB()))
+
+	// Initialize built-in board templates
+	if err := boardTemplateHandler.InitializeDefaults(); err != nil {
+		logger.Log.Error("Failed to initialize board templates", err)
+	}
 	
 	// Initialize RocketChat defaults
 	if err := rocketChatHandler.InitializeDefaults(); err != nil {
@@ -82,21 +129,56 @@ func main() {
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
-			auth.GET("/profile", middleware.AuthMiddleware(), authHandler.GetProfile)
+			auth.GET("/profile", middleware.AuthMiddleware(), middleware.RequireScope("profile"), authHandler.GetProfile)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/email-verification/resend", authHandler.ResendVerification)
+			auth.POST("/email-verification/confirm", authHandler.ConfirmEmail)
+			auth.POST("/password-reset/request", authHandler.RequestPasswordReset)
+			auth.POST("/password-reset/confirm", authHandler.ResetPassword)
+			auth.GET("/sso", oidcHandler.GetSSOConfig)
+			auth.GET("/oidc/login", oidcHandler.Login)
+			auth.GET("/oidc/callback", oidcHandler.Callback)
+			auth.POST("/oidc/exchange", oidcHandler.Exchange)
+			auth.POST("/2fa/verify", authHandler.VerifyLogin)
+
+			// Sessions, tokens and 2FA are managed only from a signed-in session
+			account := auth.Group("/")
+			account.Use(middleware.AuthMiddleware(), middleware.RequireSession())
+			{
+				account.POST("/logout", authHandler.Logout)
+				account.GET("/sessions", authHandler.GetSessions)
+				account.DELETE("/sessions", authHandler.RevokeOtherSessions)
+				account.DELETE("/sessions/:id", authHandler.RevokeSession)
+				account.GET("/tokens", authHandler.GetPersonalAccessTokens)
+				account.POST("/tokens", authHandler.CreatePersonalAccessToken)
+				account.DELETE("/tokens/:id", authHandler.DeletePersonalAccessToken)
+				account.GET("/2fa", authHandler.GetTwoFactor)
+				account.POST("/2fa/setup", authHandler.SetupTwoFactor)
+				account.POST("/2fa/enable", authHandler.EnableTwoFactor)
+				account.POST("/2fa/disable", authHandler.DisableTwoFactor)
+				account.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			}
 		}
 
-		// Protected routes
+		// Protected routes. Each group names the scope personal access tokens
+		// need, and whether tokens restricted to some boards may use it.
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware())
 		{
 			// User routes
-			users := protected.Group("/users")
+			users := protected.Group("/users", middleware.RequireScope("profile"))
 			{
 				users.GET("/:id", authHandler.GetUser)
 			}
+
+			timesheets := protected.Group("/users/me", middleware.RequireScope("tasks"), middleware.RequireAllBoards())
+			{
+				timesheets.GET("/timers", worklogHandler.GetRunningTimers)
+				timesheets.GET("/timesheet", worklogHandler.GetTimesheet)
+			}
 			
 			// Member profile routes
-			profile := protected.Group("/profile")
+			profile := protected.Group("/profile", middleware.RequireScope("profile"))
 			{
 				profile.GET("", handlers.GetMemberProfile)
 				profile.PUT("", handlers.UpdateMemberProfile)
@@ -105,16 +187,56 @@ func main() {
 			}
 
 			// Board routes
-			boards := protected.Group("/boards")
+			boards := protected.Group("/boards", middleware.RequireScope("boards"), middleware.RequireBoard("id"))
 			{
 				boards.POST("", boardHandler.CreateBoard)
 				boards.GET("", boardHandler.GetBoards)
+				boards.GET("/trash", boardHandler.GetDeletedBoards)
 				boards.GET("/:id", boardHandler.GetBoard)
 				boards.PUT("/:id", boardHandler.UpdateBoard)
+				boards.PUT("/:id/settings", boardHandler.UpdateSettings)
 				// Column routes
 				boards.GET("/:id/columns", columnHandler.GetColumns)
 				boards.POST("/:id/columns", columnHandler.CreateColumn)
+				boards.PUT("/:id/columns/reorder", columnHandler.ReorderColumns)
+				boards.PUT("/:id/columns/:colId", columnHandler.UpdateColumn)
+				boards.DELETE("/:id/columns/:colId", columnHandler.DeleteColumn)
+				boards.POST("/:id/columns/:colId/restore", columnHandler.RestoreColumn)
+				// Label routes
+				boards.GET("/:id/labels", labelHandler.GetLabels)
+				boards.POST("/:id/labels", labelHandler.CreateLabel)
+				boards.POST("/:id/labels/merge", labelHandler.MergeLabels)
+				boards.PUT("/:id/labels/:labelId", labelHandler.UpdateLabel)
+				boards.DELETE("/:id/labels/:labelId", labelHandler.DeleteLabel)
+				boards.GET("/:id/custom-fields", customFieldHandler.GetCustomFields)
+				boards.POST("/:id/custom-fields", customFieldHandler.CreateCustomField)
+				boards.PUT("/:id/custom-fields/:fieldId", customFieldHandler.UpdateCustomField)
+				boards.DELETE("/:id/custom-fields/:fieldId", customFieldHandler.DeleteCustomField)
+				boards.GET("/:id/recurring-tasks", recurringTaskHandler.GetRecurringTasks)
+				boards.POST("/:id/recurring-tasks", recurringTaskHandler.CreateRecurringTask)
+				boards.GET("/:id/recurring-tasks/:recurringId", recurringTaskHandler.GetRecurringTask)
+				boards.PUT("/:id/recurring-tasks/:recurringId", recurringTaskHandler.UpdateRecurringTask)
+				boards.DELETE("/:id/recurring-tasks/:recurringId", recurringTaskHandler.DeleteRecurringTask)
+				boards.GET("/:id/automations", automationHandler.GetAutomations)
+				boards.POST("/:id/automations", automationHandler.CreateAutomation)
+				boards.PUT("/:id/automations/:ruleId", automationHandler.UpdateAutomation)
+				boards.DELETE("/:id/automations/:ruleId", automationHandler.DeleteAutomation)
+				boards.GET("/:id/automations/:ruleId/runs", automationHandler.GetAutomationRuns)
+				boards.GET("/:id/webhooks", webhookHandler.GetWebhooks)
+				boards.POST("/:id/webhooks", webhookHandler.CreateWebhook)
+				boards.PUT("/:id/webhooks/:webhookId", webhookHandler.UpdateWebhook)
+				boards.DELETE("/:id/webhooks/:webhookId", webhookHandler.DeleteWebhook)
+				boards.POST("/:id/webhooks/:webhookId/ping", webhookHandler.PingWebhook)
+				boards.GET("/:id/webhooks/:webhookId/deliveries", webhookHandler.GetWebhookDeliveries)
+				boards.POST("/:id/webhooks/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhookDelivery)
+				boards.GET("/:id/activity", taskHandler.GetBoardActivity)
+				boards.GET("/:id/dependency-graph", taskHandler.GetDependencyGraph)
+				boards.POST("/:id/templates", boardTemplateHandler.SaveBoardAsTemplate)
+				boards.GET("/:id/orphaned-statuses", columnHandler.GetOrphanedStatuses)
+				boards.POST("/:id/orphaned-statuses/repair", columnHandler.RepairStatuses)
 				boards.DELETE("/:id", boardHandler.DeleteBoard)
+				boards.GET("/:id/trash", boardHandler.GetBoardTrash)
+				boards.POST("/:id/restore", boardHandler.RestoreBoard)
 				boards.POST("/:id/invite", boardHandler.InviteUser)
 				boards.DELETE("/:id/members/:userId", boardHandler.RemoveMember)
 				boards.PUT("/:id/members/:userId/role", boardHandler.UpdateMemberRole)
@@ -122,12 +244,23 @@ func main() {
 				// LLM routes
 				boards.GET("/:id/llm-config", handlers.GetLLMConfig)
 				boards.PUT("/:id/llm-config", handlers.UpdateLLMConfig)
-				boards.POST("/:id/generate-tasks", handlers.GenerateTasks)
+				boards.POST("/:id/generate-tasks", taskHandler.GenerateTasks)
 				boards.POST("/:id/llm-models/search", handlers.SearchLLMModels)
 			}
 
+			// Search routes
+			protected.GET("/search", middleware.RequireScope("tasks"), middleware.RequireAllBoards(), searchHandler.Search)
+
+			// Board template routes
+			boardTemplates := protected.Group("/board-templates", middleware.RequireScope("boards"))
+			{
+				boardTemplates.GET("", boardTemplateHandler.GetTemplates)
+				boardTemplates.GET("/:id", boardTemplateHandler.GetTemplate)
+				boardTemplates.DELETE("/:id", boardTemplateHandler.DeleteTemplate)
+			}
+
 			// Invitation routes
-			invitations := protected.Group("/invitations")
+			invitations := protected.Group("/invitations", middleware.RequireScope("boards"), middleware.RequireAllBoards())
 			{
 				invitations.GET("", boardHandler.GetInvitations)
 				invitations.POST("/:id/accept", boardHandler.AcceptInvitation)
@@ -135,22 +268,49 @@ func main() {
 			}
 
 			// Task routes
-			tasks := protected.Group("/boards/:id/tasks")
+			tasks := protected.Group("/boards/:id/tasks", middleware.RequireScope("tasks"), middleware.RequireBoard("id"))
 			{
 				tasks.POST("", taskHandler.CreateTask)
 				tasks.GET("", taskHandler.GetTasks)
 			}
 
-			taskRoutes := protected.Group("/tasks")
+			taskRoutes := protected.Group("/tasks", middleware.RequireScope("tasks"), middleware.RequireTaskBoard())
 			{
 				taskRoutes.GET("/:id", taskHandler.GetTask)
 				taskRoutes.PUT("/:id", taskHandler.UpdateTask)
 				taskRoutes.DELETE("/:id", taskHandler.DeleteTask)
+				taskRoutes.POST("/:id/restore", taskHandler.RestoreTask)
 				taskRoutes.PUT("/:id/move", taskHandler.MoveTask)
+				taskRoutes.GET("/:id/activity", taskHandler.GetTaskActivity)
+				taskRoutes.GET("/:id/watchers", taskHandler.GetWatchers)
+				taskRoutes.POST("/:id/watch", taskHandler.WatchTask)
+				taskRoutes.DELETE("/:id/watch", taskHandler.UnwatchTask)
+				taskRoutes.GET("/:id/subtasks", taskHandler.GetSubtasks)
+				taskRoutes.PUT("/:id/parent", taskHandler.SetParent)
+				taskRoutes.GET("/:id/checklist", taskHandler.GetChecklist)
+				taskRoutes.POST("/:id/checklist", taskHandler.CreateChecklistItem)
+				taskRoutes.PUT("/:id/checklist/reorder", taskHandler.ReorderChecklist)
+				taskRoutes.PUT("/:id/checklist/:itemId", taskHandler.UpdateChecklistItem)
+				taskRoutes.POST("/:id/checklist/:itemId/toggle", taskHandler.ToggleChecklistItem)
+				taskRoutes.DELETE("/:id/checklist/:itemId", taskHandler.DeleteChecklistItem)
+				taskRoutes.GET("/:id/links", taskHandler.GetTaskLinks)
+				taskRoutes.POST("/:id/links", taskHandler.CreateTaskLink)
+				taskRoutes.DELETE("/:id/links/:linkId", taskHandler.DeleteTaskLink)
+				taskRoutes.GET("/:id/comments", taskCommentHandler.GetComments)
+				taskRoutes.POST("/:id/comments", taskCommentHandler.CreateComment)
+				taskRoutes.PUT("/:id/comments/:commentId", taskCommentHandler.UpdateComment)
+				taskRoutes.DELETE("/:id/comments/:commentId", taskCommentHandler.DeleteComment)
+				taskRoutes.GET("/:id/comments/:commentId/history", taskCommentHandler.GetCommentHistory)
+				taskRoutes.GET("/:id/worklogs", worklogHandler.GetWorklogs)
+				taskRoutes.POST("/:id/worklogs", worklogHandler.CreateWorklog)
+				taskRoutes.PUT("/:id/worklogs/:worklogId", worklogHandler.UpdateWorklog)
+				taskRoutes.DELETE("/:id/worklogs/:worklogId", worklogHandler.DeleteWorklog)
+				taskRoutes.POST("/:id/timer/start", worklogHandler.StartTimer)
+				taskRoutes.POST("/:id/timer/stop", worklogHandler.StopTimer)
 			}
 
 			// Chat routes
-			chat := protected.Group("/chat")
+			chat := protected.Group("/chat", middleware.RequireScope("chat"), middleware.RequireBoard("boardId"))
 			{
 				chat.POST("/boards/:boardId/messages", chatHandler.SendMessage)
 				chat.GET("/boards/:boardId/messages", chatHandler.GetMessages)
@@ -160,7 +320,7 @@ func main() {
 			}
 
 			// Private message routes
-			privateMessages := protected.Group("/private-messages")
+			privateMessages := protected.Group("/private-messages", middleware.RequireScope("messages"))
 			{
 				privateMessages.POST("", privateMessageHandler.SendPrivateMessage)
 				privateMessages.GET("/conversations", privateMessageHandler.GetConversations)
@@ -170,7 +330,7 @@ func main() {
 			}
 
 			// Appointment routes
-			appointments := protected.Group("/appointments")
+			appointments := protected.Group("/appointments", middleware.RequireScope("appointments"))
 			{
 				appointments.GET("", handlers.GetAppointments)
 				appointments.POST("", handlers.CreateAppointment)
@@ -179,25 +339,17 @@ func main() {
 			}
 
 			// WebSocket routes
-			protected.GET("/ws/:id", func(c *gin.Context) {
-				boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-				if err != nil {
-					c.JSON(400, gin.H{"error": "Invalid board ID"})
-					return
-				}
-				c.Set("board_id", uint(boardID))
-				hub.HandleWebSocket(c)
-			})
+			protected.GET("/ws/:id", middleware.RequireScope("boards"), middleware.RequireBoard("id"), boardHandler.ConnectWebSocket)
 
 			// WebSocket route for private messages
-			protected.GET("/ws/private", func(c *gin.Context) {
+			protected.GET("/ws/private", middleware.RequireScope("messages"), func(c *gin.Context) {
 				// No board ID for private messages, just use user ID
 				c.Set("board_id", uint(0)) // Use 0 as a special value for private messages
 				hub.HandleWebSocket(c)
 			})
 
 			// WebSocket route for typing notifications
-			protected.POST("/private-messages/typing", func(c *gin.Context) {
+			protected.POST("/private-messages/typing", middleware.RequireScope("messages"), func(c *gin.Context) {
 				userID := middleware.GetUserID(c)
 				
 				var req struct {
@@ -226,7 +378,7 @@ func main() {
 			
 			// Protected RocketChat routes
 			rcProtected := rocketChat.Group("/")
-			rcProtected.Use(middleware.AuthMiddleware())
+			rcProtected.Use(middleware.AuthMiddleware(), middleware.RequireScope("chat"))
 			{
 				// Rooms
 				rcProtected.GET("/rooms.get", rocketChatHandler.GetRooms)
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, which tells
+// them apart from session JWTs and makes leaked tokens easy to scan for.
+const PersonalAccessTokenPrefix = "tfp_"
+
+// NewPersonalAccessToken returns a random personal access token and the hash
+// to store for it.
+func NewPersonalAccessToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashPersonalAccessToken(token), nil
+}
+
+// HashPersonalAccessToken returns the stored form of a personal access token.
+func HashPersonalAccessToken(token string) string {
+	return hashToken(token)
+}
+
+// IsPersonalAccessToken reports whether a bearer token is a personal access
+// token rather than a JWT.
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
@@ -1,14 +1,21 @@
 package database
 
 import (
+	"errors"
+	"math"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"kanban-backend/internal/models"
 	"kanban-backend/internal/logger"
+	"kanban-backend/internal/search"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormlogger "gorm.io/gorm/logger"
 )
 
@@ -43,6 +50,25 @@ func InitDatabase() {
 		logger.Log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Column statuses used to be unique across all boards; they are now
+	// unique per board among live columns under idx_column_board_status
+	if DB.Migrator().HasIndex(&models.Column{}, "idx_board_status") {
+		if err := DB.Migrator().DropIndex(&models.Column{}, "idx_board_status"); err != nil {
+			logger.Log.Fatalf("Failed to drop legacy column index: %v", err)
+		}
+	}
+
+	// Full-text search triggers are recreated by search.New after migrating;
+	// migrations must not run them, the FTS5 module may not be compiled in
+	if DB.Dialector.Name() == "sqlite" {
+		if err := search.DropSQLiteTriggers(DB); err != nil {
+			logger.Log.Fatalf("Failed to drop search triggers: %v", err)
+		}
+	}
+
+	// Accounts from before email verification count as verified
+	verifyExistingUsers := DB.Migrator().HasTable(&models.User{}) && !DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
 	// Auto-migrate the schema in dependency order
 	// First migrate base models
 	err = DB.AutoMigrate(
@@ -51,18 +77,66 @@ func InitDatabase() {
 		&models.BoardMember{},
 		&models.MemberPermission{},
 		&models.BoardSettings{},
+		&models.Label{},
 		&models.Task{},
-		&models.TaskTag{},
+		&models.TaskLabel{},
+		&models.TaskAssignee{},
+		&models.TaskWatcher{},
+		&models.CustomFieldDefinition{},
+		&models.TaskCustomFieldValue{},
         &models.Column{},
 		&models.Invitation{},
 		&models.ChatMessage{},
 		&models.PrivateMessage{},
 		&models.Appointment{},
+		&models.BoardTemplate{},
+		&models.TaskActivity{},
+		&models.TaskComment{},
+		&models.TaskCommentRevision{},
+		&models.ChecklistItem{},
+		&models.TaskLink{},
+		&models.Worklog{},
+		&models.RecurringTask{},
+		&models.RecurringTaskRun{},
+		&models.AutomationRule{},
+		&models.AutomationRun{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Session{},
+		&models.PersonalAccessToken{},
+		&models.OneTimeToken{},
+		&models.UserIdentity{},
+		&models.OIDCLoginAttempt{},
+		&models.UserTwoFactor{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
 	)
 	if err != nil {
 		logger.Log.Fatalf("Failed to migrate base models: %v", err)
 	}
 
+	if verifyExistingUsers {
+		if err := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			logger.Log.Fatalf("Failed to mark existing users verified: %v", err)
+		}
+	}
+
+	if err := migrateTaskTags(DB); err != nil {
+		logger.Log.Fatalf("Failed to migrate task tags to labels: %v", err)
+	}
+
+	// Tasks from before multiple assignees only have assignee_id set
+	if err := DB.Exec(`INSERT INTO task_assignees (task_id, user_id)
+		SELECT id, assignee_id FROM tasks
+		WHERE assignee_id IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM task_assignees WHERE task_assignees.task_id = tasks.id)`).Error; err != nil {
+		logger.Log.Fatalf("Failed to migrate task assignees: %v", err)
+	}
+
+	if err := migrateActualHours(DB); err != nil {
+		logger.Log.Fatalf("Failed to migrate actual hours to worklogs: %v", err)
+	}
+
 	// Then migrate RocketChat models in dependency order
 	// 1. Users first (no dependencies)
 	err = DB.AutoMigrate(&models.RocketChatUser{})
@@ -98,6 +172,103 @@ func InitDatabase() {
 	logger.Log.Info("Database connected and migrated successfully")
 }
 
+// migrateTaskTags folds the free-text task_tags of earlier versions into board
+// labels, one label per board and case-insensitive name, and drops the table.
+func migrateTaskTags(db *gorm.DB) error {
+	if !db.Migrator().HasTable("task_tags") {
+		return nil
+	}
+
+	var rows []struct {
+		TaskID  uint
+		BoardID uint
+		Tag     string
+	}
+	if err := db.Table("task_tags").
+		Select("task_tags.task_id, tasks.board_id, task_tags.tag").
+		Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+		Order("task_tags.id asc").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		labelIDs := make(map[string]uint) // board ID and lower-cased name
+		for _, row := range rows {
+			name := strings.TrimSpace(row.Tag)
+			if name == "" {
+				continue
+			}
+			key := strconv.FormatUint(uint64(row.BoardID), 10) + ":" + strings.ToLower(name)
+
+			labelID, ok := labelIDs[key]
+			if !ok {
+				var label models.Label
+				err := tx.Where("board_id = ? AND LOWER(name) = ?", row.BoardID, strings.ToLower(name)).First(&label).Error
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					label = models.Label{BoardID: row.BoardID, Name: name, Color: models.DefaultLabelColor}
+					err = tx.Create(&label).Error
+				}
+				if err != nil {
+					return err
+				}
+				labelID = label.ID
+				labelIDs[key] = labelID
+			}
+
+			taskLabel := models.TaskLabel{TaskID: row.TaskID, LabelID: labelID}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&taskLabel).Error; err != nil {
+				return err
+			}
+		}
+
+		logger.Log.Infof("Migrated %d task tags to labels", len(rows))
+		return tx.Migrator().DropTable("task_tags")
+	})
+}
+
+// migrateActualHours turns the hand-entered actual hours of earlier versions
+// into one worklog per task, by the assignee or else the creator, so that the
+// sum of worklogs keeps the recorded time.
+func migrateActualHours(db *gorm.DB) error {
+	var tasks []models.Task
+	if err := db.Unscoped().
+		Where("actual_hours > 0").
+		Where("NOT EXISTS (SELECT 1 FROM worklogs WHERE worklogs.task_id = tasks.id)").
+		Find(&tasks).Error; err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, task := range tasks {
+			userID := task.CreatedBy
+			if task.AssigneeID != nil {
+				userID = *task.AssigneeID
+			}
+			seconds := int64(math.Round(*task.ActualHours * 3600))
+			endedAt := task.CreatedAt.Add(time.Duration(seconds) * time.Second)
+			worklog := models.Worklog{
+				TaskID:    task.ID,
+				BoardID:   task.BoardID,
+				UserID:    userID,
+				StartedAt: task.CreatedAt,
+				EndedAt:   &endedAt,
+				Seconds:   seconds,
+				Note:      "Imported from actual hours",
+			}
+			if err := tx.Create(&worklog).Error; err != nil {
+				return err
+			}
+		}
+
+		logger.Log.Infof("Migrated actual hours of %d tasks to worklogs", len(tasks))
+		return nil
+	})
+}
+
 func loggerzap() gormlogger.Interface {
 	return gormlogger.Default.LogMode(gormlogger.Info)
 }
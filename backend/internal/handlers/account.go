@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/mail"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/oidc"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = time.Hour
+
+	// oneTimeTokenCooldown is how soon another email of the same kind can be
+	// requested for an account.
+	oneTimeTokenCooldown = time.Minute
+)
+
+var errInvalidOneTimeToken = errors.New("invalid or expired token")
+
+// emailVerificationRequired reports whether REQUIRE_EMAIL_VERIFICATION keeps
+// unverified accounts from signing in.
+func emailVerificationRequired() bool {
+	return strings.EqualFold(os.Getenv("REQUIRE_EMAIL_VERIFICATION"), "true")
+}
+
+// ResendVerification emails a new verification link. The response is the same
+// whether or not the account exists, so it cannot be used to probe emails.
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err == nil && user.EmailVerifiedAt == nil {
+		if err := h.sendVerificationEmail(&user); err != nil {
+			logger.Log.Errorf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists and is not verified yet, a verification email is on its way"})
+}
+
+// ConfirmEmail verifies the address a verification link was sent to.
+func (h *AuthHandler) ConfirmEmail(c *gin.Context) {
+	var req models.ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		token, err := consumeOneTimeToken(tx, req.Token, models.OneTimeTokenEmailVerification)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now().UTC()).Error
+	})
+	if errors.Is(err, errInvalidOneTimeToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// RequestPasswordReset emails a password reset link. Like ResendVerification
+// it answers the same for unknown emails.
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var req models.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Passwords of SSO-only domains are never used, so there is nothing to reset
+	var user models.User
+	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err == nil && !oidc.Enforced(user.Email) {
+		if err := h.sendPasswordResetEmail(&user); err != nil {
+			logger.Log.Errorf("Failed to send password reset email to user %d: %v", user.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a password reset email is on its way"})
+}
+
+// ResetPassword sets a new password with a reset link, signs the account out
+// everywhere and deletes its personal access tokens. Receiving the link also
+// proves the email address.
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		token, err := consumeOneTimeToken(tx, req.Token, models.OneTimeTokenPasswordReset)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(&models.User{}).Where("id = ?", token.UserID).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", now).Error; err != nil {
+			return err
+		}
+		// Other links sent before this reset must not undo it
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, models.OneTimeTokenPasswordReset).
+			Delete(&models.OneTimeToken{}).Error; err != nil {
+			return err
+		}
+		// Someone who took over the account must not keep API access either
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+			return err
+		}
+		return revokeSessions(tx.Where("user_id = ?", token.UserID)).Error
+	})
+	if errors.Is(err, errInvalidOneTimeToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset link"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+func (h *AuthHandler) sendVerificationEmail(user *models.User) error {
+	token, err := issueEmailToken(database.GetDB(), user.ID, models.OneTimeTokenEmailVerification, emailVerificationTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	link := mail.AppURL() + "/verify-email?token=" + url.QueryEscape(token)
+	return h.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening this link:\n\n%s\n\n"+
+			"The link expires in 24 hours. If you did not create an account, you can ignore this email.\n",
+			user.Name, link),
+	})
+}
+
+func (h *AuthHandler) sendPasswordResetEmail(user *models.User) error {
+	token, err := issueEmailToken(database.GetDB(), user.ID, models.OneTimeTokenPasswordReset, passwordResetTTL)
+	if err != nil || token == "" {
+		return err
+	}
+
+	link := mail.AppURL() + "/reset-password?token=" + url.QueryEscape(token)
+	return h.mailer.Send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your account. To choose a new password, open this link:\n\n%s\n\n"+
+			"Resetting signs you out everywhere and deletes your personal access tokens.\n\n"+
+			"The link expires in an hour and works once. If you did not ask for it, you can ignore this email.\n",
+			user.Name, link),
+	})
+}
+
+// issueEmailToken issues a token for an email link like issueOneTimeToken,
+// but returns an empty token without error when one was issued within
+// oneTimeTokenCooldown.
+func issueEmailToken(db *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	var recent int64
+	if err := db.Model(&models.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, time.Now().UTC().Add(-oneTimeTokenCooldown)).
+		Count(&recent).Error; err != nil {
+		return "", err
+	}
+	if recent > 0 {
+		return "", nil
+	}
+	return issueOneTimeToken(db, userID, purpose, ttl)
+}
+
+// issueOneTimeToken stores a new token for purpose and returns it, replacing
+// the user's earlier ones.
+func issueOneTimeToken(db *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now().UTC()
+	token, hash, err := auth.NewOneTimeToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ?", userID, purpose).Delete(&models.OneTimeToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OneTimeToken{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: hash,
+			ExpiresAt: now.Add(ttl),
+			CreatedAt: now,
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeOneTimeToken marks a token for purpose used and returns it. Tokens
+// that are unknown, expired or already used yield errInvalidOneTimeToken.
+func consumeOneTimeToken(tx *gorm.DB, token, purpose string) (*models.OneTimeToken, error) {
+	var stored models.OneTimeToken
+	if err := tx.Where("token_hash = ? AND purpose = ?", auth.HashOneTimeToken(token), purpose).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidOneTimeToken
+		}
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if stored.UsedAt != nil || !now.Before(stored.ExpiresAt) {
+		return nil, errInvalidOneTimeToken
+	}
+
+	// The used_at condition lets only one of two concurrent uses through
+	result := tx.Model(&models.OneTimeToken{}).Where("id = ? AND used_at IS NULL", stored.ID).Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errInvalidOneTimeToken
+	}
+	return &stored, nil
+}
@@ -4,18 +4,25 @@ import (
 	"net/http"
 	"strconv"
 
-	"kanban-backend/internal/auth"
 	"kanban-backend/internal/database"
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/mail"
 	"kanban-backend/internal/models"
+	"kanban-backend/internal/oidc"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type AuthHandler struct{}
+// defaultAvatarURL is the avatar of new accounts.
+const defaultAvatarURL = "https://images.unsplash.com/photo-1472099645785-5658abf4ff4e?w=100&h=100&fit=crop&crop=face"
 
-func NewAuthHandler() *AuthHandler {
-	return &AuthHandler{}
+type AuthHandler struct {
+	mailer mail.Mailer
+}
+
+func NewAuthHandler(mailer mail.Mailer) *AuthHandler {
+	return &AuthHandler{mailer: mailer}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -25,6 +32,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	if oidc.Enforced(req.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Accounts of this email domain sign in with SSO", "sso_required": true})
+		return
+	}
+
 	// Check if user already exists
 	var existingUser models.User
 	if err := database.GetDB().Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
@@ -44,7 +56,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Email:    req.Email,
 		Name:     req.Name,
 		Password: string(hashedPassword),
-		Avatar:   "https://images.unsplash.com/photo-1472099645785-5658abf4ff4e?w=100&h=100&fit=crop&crop=face",
+		Avatar:   defaultAvatarURL,
 	}
 
 	if err := database.GetDB().Create(&user).Error; err != nil {
@@ -52,25 +64,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
+	if err := h.sendVerificationEmail(&user); err != nil {
+		logger.Log.Errorf("Failed to send verification email to user %d: %v", user.ID, err)
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		Avatar:    user.Avatar,
-		CreatedAt: user.CreatedAt,
+	// Accounts that must verify their email first get no session yet
+	if emailVerificationRequired() {
+		c.JSON(http.StatusCreated, gin.H{
+			"user":    newAccountResponse(&user),
+			"message": "Check your email to verify your account",
+		})
+		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"user":  userResponse,
-		"token": token,
-	})
+	startSession(c, http.StatusCreated, &user)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -80,6 +87,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	// Domains on SSO have no password login, whatever password the account has
+	if oidc.Enforced(req.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Accounts of this email domain sign in with SSO", "sso_required": true})
+		return
+	}
+
 	// Find user
 	var user models.User
 	if err := database.GetDB().Where("email = ?", req.Email).First(&user).Error; err != nil {
@@ -93,25 +106,12 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, err := auth.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+	if emailVerificationRequired() && user.EmailVerifiedAt == nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Email address not verified", "email_verified": false})
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		Avatar:    user.Avatar,
-		CreatedAt: user.CreatedAt,
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"user":  userResponse,
-		"token": token,
-	})
+	completeLogin(c, http.StatusOK, &user)
 }
 
 func (h *AuthHandler) GetProfile(c *gin.Context) {
@@ -123,15 +123,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	userResponse := models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		Avatar:    user.Avatar,
-		CreatedAt: user.CreatedAt,
-	}
-
-	c.JSON(http.StatusOK, userResponse)
+	c.JSON(http.StatusOK, newAccountResponse(&user))
 }
 
 // GetUser retrieves a user's public profile by ID
@@ -157,4 +149,18 @@ func (h *AuthHandler) GetUser(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, userResponse)
-}
\ No newline at end of file
+}
+
+// newAccountResponse describes a user to themselves, including whether their
+// email is verified.
+func newAccountResponse(user *models.User) models.UserResponse {
+	verified := user.EmailVerifiedAt != nil
+	return models.UserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Avatar:        user.Avatar,
+		EmailVerified: &verified,
+		CreatedAt:     user.CreatedAt,
+	}
+}
@@ -0,0 +1,497 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxAutomationDepth bounds how often changes made by rules may trigger
+	// further rules for a single change by a user.
+	maxAutomationDepth = 3
+
+	// automationRunHistory is how many runs are kept in each rule's log.
+	automationRunHistory = 200
+)
+
+// automationEvent is a trigger firing for a task. depth counts the rules
+// that led to it.
+type automationEvent struct {
+	trigger string
+	changes []models.FieldChange
+	depth   int
+}
+
+// automationNotification is a message from a notify action. It is sent once
+// the transaction the rule ran in has committed.
+type automationNotification struct {
+	userIDs []uint
+	data    gin.H
+}
+
+// automationResult collects the side effects of the rules that ran for a
+// change.
+type automationResult struct {
+	notifications []automationNotification
+}
+
+// send delivers the notifications queued by notify actions.
+func (r *automationResult) send(hub *websocket.Hub) {
+	if r == nil {
+		return
+	}
+	for _, notification := range r.notifications {
+		for _, id := range notification.userIDs {
+			hub.BroadcastPrivateMessage(id, "automation_notification", notification.data)
+		}
+	}
+}
+
+// automationState is what conditions are tested against.
+type automationState struct {
+	task        *models.Task
+	labelIDs    []uint
+	assigneeIDs []uint
+}
+
+// runAutomations runs the board's enabled rules for trigger firing on task
+// inside tx, with changes being what fired it. Each rule runs in a savepoint,
+// so a failing rule is logged and rolled back without affecting the change
+// that triggered it. Changes made by rules trigger further rules, but every
+// rule runs at most once per call and chains stop after maxAutomationDepth.
+func runAutomations(tx *gorm.DB, task *models.Task, actorID uint, trigger string, changes []models.FieldChange) (*automationResult, error) {
+	result := &automationResult{}
+
+	var rules []models.AutomationRule
+	if err := tx.Where("board_id = ? AND enabled = ?", task.BoardID, true).Order("position asc, id asc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	fired := make(map[uint]bool)
+	queue := automationEvents(trigger, changes, 0)
+	for len(queue) > 0 {
+		event := queue[0]
+		queue = queue[1:]
+
+		for i := range rules {
+			rule := &rules[i]
+			if rule.Trigger != event.trigger {
+				continue
+			}
+			state, err := loadAutomationState(tx, task)
+			if err != nil {
+				return nil, err
+			}
+			if !automationConditionsHold(rule.Conditions, state, event.changes) {
+				continue
+			}
+
+			if fired[rule.ID] || event.depth > maxAutomationDepth {
+				reason := "rule already ran for this change"
+				if !fired[rule.ID] {
+					reason = "too many rules triggered each other"
+				}
+				if err := logAutomationRun(tx, rule, task, event, models.AutomationRunLoopPrevented, nil, reason); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			fired[rule.ID] = true
+
+			savepoint := fmt.Sprintf("automation_rule_%d", rule.ID)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return nil, err
+			}
+			ruleChanges, notifications, err := applyAutomationRule(tx, rule, task, actorID)
+			if err != nil {
+				if rollbackErr := tx.RollbackTo(savepoint).Error; rollbackErr != nil {
+					return nil, rollbackErr
+				}
+				// Drop whatever the failed rule changed on the task in memory
+				if reloadErr := tx.First(task, task.ID).Error; reloadErr != nil {
+					return nil, reloadErr
+				}
+				if err := logAutomationRun(tx, rule, task, event, models.AutomationRunFailed, nil, err.Error()); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			if err := logAutomationRun(tx, rule, task, event, models.AutomationRunSucceeded, ruleChanges, ""); err != nil {
+				return nil, err
+			}
+			result.notifications = append(result.notifications, notifications...)
+			queue = append(queue, automationEvents(models.AutomationTriggerTaskUpdated, ruleChanges, event.depth+1)...)
+		}
+	}
+
+	return result, nil
+}
+
+// automationEvents lists the triggers a change fires. Status changes also
+// fire task_moved; changes that changed nothing fire nothing.
+func automationEvents(trigger string, changes []models.FieldChange, depth int) []automationEvent {
+	if trigger != models.AutomationTriggerTaskCreated && len(changes) == 0 {
+		return nil
+	}
+
+	events := []automationEvent{{trigger: trigger, changes: changes, depth: depth}}
+	if trigger == models.AutomationTriggerTaskUpdated && findFieldChange(changes, "status") != nil {
+		events = append(events, automationEvent{trigger: models.AutomationTriggerTaskMoved, changes: changes, depth: depth})
+	}
+	return events
+}
+
+func loadAutomationState(tx *gorm.DB, task *models.Task) (*automationState, error) {
+	state := &automationState{task: task}
+	if err := tx.Model(&models.TaskLabel{}).Where("task_id = ?", task.ID).Pluck("label_id", &state.labelIDs).Error; err != nil {
+		return nil, err
+	}
+	assigneeIDs, err := taskUserIDs(tx, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	state.assigneeIDs = assigneeIDs
+	return state, nil
+}
+
+// automationConditionsHold reports whether every condition holds for state,
+// with changes being the change that fired the rule.
+func automationConditionsHold(conditions []models.AutomationCondition, state *automationState, changes []models.FieldChange) bool {
+	for _, condition := range conditions {
+		if !automationConditionHolds(condition, state, changes) {
+			return false
+		}
+	}
+	return true
+}
+
+func automationConditionHolds(condition models.AutomationCondition, state *automationState, changes []models.FieldChange) bool {
+	switch condition.Field {
+	case "label_id", "assignee_id":
+		ids, changeField := state.labelIDs, "labels"
+		if condition.Field == "assignee_id" {
+			ids, changeField = state.assigneeIDs, "assignee_ids"
+		}
+		id, _ := strconv.ParseUint(condition.Value, 10, 32)
+		switch condition.Operator {
+		case "contains":
+			return containsUint(ids, uint(id))
+		case "not_contains":
+			return !containsUint(ids, uint(id))
+		case "is_empty":
+			return len(ids) == 0
+		case "is_not_empty":
+			return len(ids) > 0
+		case "changed":
+			return findFieldChange(changes, changeField) != nil
+		}
+		return false
+	}
+
+	value := automationFieldValue(state.task, condition.Field)
+	change := findFieldChange(changes, condition.Field)
+	switch condition.Operator {
+	case "equals":
+		return strings.EqualFold(value, condition.Value)
+	case "not_equals":
+		return !strings.EqualFold(value, condition.Value)
+	case "contains":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+	case "is_empty":
+		return value == ""
+	case "is_not_empty":
+		return value != ""
+	case "changed":
+		return change != nil
+	case "changed_to":
+		return change != nil && change.New != nil && strings.EqualFold(fmt.Sprint(change.New), condition.Value)
+	case "changed_from":
+		return change != nil && change.Old != nil && strings.EqualFold(fmt.Sprint(change.Old), condition.Value)
+	}
+	return false
+}
+
+// automationFieldValue returns the value of a scalar field conditions test.
+func automationFieldValue(task *models.Task, field string) string {
+	switch field {
+	case "status":
+		return task.Status
+	case "priority":
+		return task.Priority
+	case "category":
+		return task.Category
+	case "title":
+		return task.Title
+	}
+	return ""
+}
+
+func findFieldChange(changes []models.FieldChange, field string) *models.FieldChange {
+	for i := range changes {
+		if changes[i].Field == field {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+// applyAutomationRule runs the actions of rule on task inside tx and audits
+// what they changed. actorID is the user whose change triggered the rule.
+func applyAutomationRule(tx *gorm.DB, rule *models.AutomationRule, task *models.Task, actorID uint) ([]models.FieldChange, []automationNotification, error) {
+	labels, err := taskLabelNames(tx, task.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	assignees, err := taskUserIDs(tx, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	before := taskFields(task, labels, assignees)
+
+	notifications := []automationNotification{}
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case models.AutomationActionSetPriority:
+			task.Priority = action.Value
+		case models.AutomationActionSetCategory:
+			task.Category = action.Value
+		case models.AutomationActionMoveTo:
+			if task.Status == action.Value {
+				continue
+			}
+			statuses, err := boardStatuses(tx, task.BoardID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !containsStatus(statuses, action.Value) {
+				return nil, nil, fmt.Errorf("column %q no longer exists", action.Value)
+			}
+			// Rules get no exception from the board's guards on moves
+			if open, err := openSubtasksBlockingDone(tx, task, action.Value); err != nil {
+				return nil, nil, err
+			} else if open > 0 {
+				return nil, nil, fmt.Errorf("task has %d open subtasks", open)
+			}
+			if blockers, err := blockersPreventingStart(tx, task, action.Value); err != nil {
+				return nil, nil, err
+			} else if len(blockers) > 0 {
+				return nil, nil, fmt.Errorf("task is blocked by open tasks %v", blockers)
+			}
+			if violation, err := checkWIPLimit(tx, task.BoardID, action.Value, 1); err != nil {
+				return nil, nil, err
+			} else if violation != nil && !wipWarnOnly(tx, task.BoardID) {
+				return nil, nil, fmt.Errorf("column %q is at its WIP limit of %d", action.Value, violation.WIPLimit)
+			}
+			position, err := nextTaskPosition(tx, task.BoardID, action.Value, task.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			task.Status = action.Value
+			task.Position = position
+		case models.AutomationActionAssign, models.AutomationActionUnassign:
+			if err := applyAutomationAssignees(tx, task, action); err != nil {
+				return nil, nil, err
+			}
+		case models.AutomationActionAddLabel, models.AutomationActionRemoveLabel:
+			if err := applyAutomationLabel(tx, task, action); err != nil {
+				return nil, nil, err
+			}
+		case models.AutomationActionFillActualHours:
+			if err := fillActualHours(tx, task, actorID, rule); err != nil {
+				return nil, nil, err
+			}
+		case models.AutomationActionNotify:
+			notification, err := automationNotify(tx, rule, task, action)
+			if err != nil {
+				return nil, nil, err
+			}
+			notifications = append(notifications, notification)
+		default:
+			return nil, nil, fmt.Errorf("unknown action %q", action.Type)
+		}
+	}
+
+	if err := tx.Save(task).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if labels, err = taskLabelNames(tx, task.ID); err != nil {
+		return nil, nil, err
+	}
+	if assignees, err = taskUserIDs(tx, &models.TaskAssignee{}, task.ID); err != nil {
+		return nil, nil, err
+	}
+	changes := diffTaskFields(before, taskFields(task, labels, assignees))
+	if err := recordTaskActivity(tx, task, 0, models.TaskActionUpdated, models.ActivitySourceAutomation, changes); err != nil {
+		return nil, nil, err
+	}
+	return changes, notifications, nil
+}
+
+// applyAutomationAssignees adds or removes assignees. Unassign without
+// user_ids removes everyone.
+func applyAutomationAssignees(tx *gorm.DB, task *models.Task, action models.AutomationAction) error {
+	current, err := taskUserIDs(tx, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		return err
+	}
+
+	ids := []uint{}
+	if action.Type == models.AutomationActionAssign {
+		var count int64
+		if err := tx.Model(&models.BoardMember{}).Where("board_id = ? AND user_id IN ?", task.BoardID, action.UserIDs).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(uniqueUints(action.UserIDs)) {
+			return errors.New("an assignee is no longer a board member")
+		}
+		// Keep the current first assignee first so assignee_id stays put
+		ids = uniqueUints(append(current, action.UserIDs...))
+	} else if len(action.UserIDs) > 0 {
+		for _, id := range current {
+			if !containsUint(action.UserIDs, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return setTaskAssignees(tx, task, ids)
+}
+
+func applyAutomationLabel(tx *gorm.DB, task *models.Task, action models.AutomationAction) error {
+	if action.LabelID == nil {
+		return errors.New("label_id is required")
+	}
+	if action.Type == models.AutomationActionRemoveLabel {
+		return tx.Where("task_id = ? AND label_id = ?", task.ID, *action.LabelID).Delete(&models.TaskLabel{}).Error
+	}
+
+	var label models.Label
+	if err := tx.Where("id = ? AND board_id = ?", *action.LabelID, task.BoardID).First(&label).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errUnknownLabel
+		}
+		return err
+	}
+	return tx.Where(models.TaskLabel{TaskID: task.ID, LabelID: label.ID}).FirstOrCreate(&models.TaskLabel{}).Error
+}
+
+// fillActualHours logs a task's estimate as one worklog when no time has
+// been logged on it yet, so its actual hours match the estimate. The worklog
+// belongs to the user whose change triggered the rule, or the task's creator.
+func fillActualHours(tx *gorm.DB, task *models.Task, actorID uint, rule *models.AutomationRule) error {
+	if task.ActualHours != nil || task.EstimatedHours == nil || *task.EstimatedHours <= 0 {
+		return nil
+	}
+
+	userID := actorID
+	if userID == 0 {
+		userID = task.CreatedBy
+	}
+	seconds := int64(*task.EstimatedHours * 3600)
+	endedAt := time.Now()
+	worklog := models.Worklog{
+		TaskID:    task.ID,
+		BoardID:   task.BoardID,
+		UserID:    userID,
+		StartedAt: endedAt.Add(-time.Duration(seconds) * time.Second),
+		EndedAt:   &endedAt,
+		Seconds:   seconds,
+		Note:      "Logged by automation " + rule.Name,
+	}
+	if err := tx.Create(&worklog).Error; err != nil {
+		return err
+	}
+
+	hours, err := finishedWorklogHours(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	task.ActualHours = hours
+	return nil
+}
+
+// automationNotify resolves the recipients of a notify action.
+func automationNotify(tx *gorm.DB, rule *models.AutomationRule, task *models.Task, action models.AutomationAction) (automationNotification, error) {
+	var userIDs []uint
+	var err error
+	switch action.Target {
+	case "owners":
+		err = tx.Model(&models.BoardMember{}).Where("board_id = ? AND role = ?", task.BoardID, "owner").Pluck("user_id", &userIDs).Error
+	case "creator":
+		userIDs = []uint{task.CreatedBy}
+	case "assignees":
+		userIDs, err = taskUserIDs(tx, &models.TaskAssignee{}, task.ID)
+	case "watchers":
+		userIDs, err = taskUserIDs(tx, &models.TaskWatcher{}, task.ID)
+	case "users":
+		// Users who have left the board are skipped
+		err = tx.Model(&models.BoardMember{}).Where("board_id = ? AND user_id IN ?", task.BoardID, action.UserIDs).Pluck("user_id", &userIDs).Error
+	default:
+		err = fmt.Errorf("unknown notify target %q", action.Target)
+	}
+	if err != nil {
+		return automationNotification{}, err
+	}
+
+	message := action.Value
+	if message == "" {
+		message = rule.Name
+	}
+	return automationNotification{
+		userIDs: uniqueUints(userIDs),
+		data: gin.H{
+			"rule_id":    rule.ID,
+			"rule_name":  rule.Name,
+			"board_id":   task.BoardID,
+			"task_id":    task.ID,
+			"task_title": task.Title,
+			"message":    message,
+		},
+	}, nil
+}
+
+// logAutomationRun writes an entry to a rule's execution log and drops the
+// entries beyond automationRunHistory.
+func logAutomationRun(tx *gorm.DB, rule *models.AutomationRule, task *models.Task, event automationEvent, status string, changes []models.FieldChange, message string) error {
+	if changes == nil {
+		changes = []models.FieldChange{}
+	}
+	run := models.AutomationRun{
+		RuleID:  rule.ID,
+		BoardID: rule.BoardID,
+		TaskID:  task.ID,
+		Trigger: event.trigger,
+		Depth:   event.depth,
+		Status:  status,
+		Changes: changes,
+		Error:   message,
+	}
+	if err := tx.Create(&run).Error; err != nil {
+		return err
+	}
+
+	var cutoff []uint
+	if err := tx.Model(&models.AutomationRun{}).
+		Where("rule_id = ?", rule.ID).
+		Order("id desc").Offset(automationRunHistory).Limit(1).
+		Pluck("id", &cutoff).Error; err != nil {
+		return err
+	}
+	if len(cutoff) == 0 {
+		return nil
+	}
+	return tx.Where("rule_id = ? AND id <= ?", rule.ID, cutoff[0]).Delete(&models.AutomationRun{}).Error
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// automationOperators lists the condition operators each field accepts.
+var automationOperators = map[string][]string{
+	"status":      {"equals", "not_equals", "changed", "changed_to", "changed_from"},
+	"priority":    {"equals", "not_equals", "changed", "changed_to", "changed_from"},
+	"category":    {"equals", "not_equals", "contains", "is_empty", "is_not_empty", "changed", "changed_to", "changed_from"},
+	"title":       {"equals", "not_equals", "contains", "changed"},
+	"label_id":    {"contains", "not_contains", "is_empty", "is_not_empty", "changed"},
+	"assignee_id": {"contains", "not_contains", "is_empty", "is_not_empty", "changed"},
+}
+
+var automationNotifyTargets = []string{"owners", "creator", "assignees", "watchers", "users"}
+
+type AutomationHandler struct {
+	hub *websocket.Hub
+}
+
+func NewAutomationHandler(hub *websocket.Hub) *AutomationHandler {
+	return &AutomationHandler{hub: hub}
+}
+
+// GetAutomations returns a board's automation rules in the order they run.
+func (h *AutomationHandler) GetAutomations(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	rules := []models.AutomationRule{}
+	if err := database.GetDB().Where("board_id = ?", boardID).Order("position asc, id asc").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch automations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateAutomation adds a rule to the end of a board's rules. Requires
+// manage_board.
+func (h *AutomationHandler) CreateAutomation(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateAutomationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	if !checkAutomationRule(c, db, uint(boardID), req.Conditions, req.Actions) {
+		return
+	}
+
+	rule := models.AutomationRule{
+		BoardID:    uint(boardID),
+		Name:       req.Name,
+		Enabled:    true,
+		Trigger:    req.Trigger,
+		Conditions: req.Conditions,
+		Actions:    req.Actions,
+		CreatedBy:  userID,
+	}
+	if rule.Conditions == nil {
+		rule.Conditions = []models.AutomationCondition{}
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Position != nil {
+		rule.Position = *req.Position
+	} else {
+		var maxPosition int
+		if err := db.Model(&models.AutomationRule{}).
+			Where("board_id = ?", boardID).
+			Select("COALESCE(MAX(position),0)").
+			Scan(&maxPosition).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create automation"})
+			return
+		}
+		rule.Position = maxPosition + 1
+	}
+
+	if err := db.Create(&rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create automation"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(rule.BoardID, "automation_created", rule)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateAutomation changes, enables or disables a rule. Requires manage_board.
+func (h *AutomationHandler) UpdateAutomation(c *gin.Context) {
+	rule, ok := loadBoardAutomation(c, "manage_board")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAutomationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		rule.Name = *req.Name
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Trigger != nil {
+		rule.Trigger = *req.Trigger
+	}
+	if req.Conditions != nil {
+		rule.Conditions = req.Conditions
+	}
+	if req.Actions != nil {
+		rule.Actions = req.Actions
+	}
+	if req.Position != nil {
+		rule.Position = *req.Position
+	}
+
+	// Rules broken by a deleted label or departed member can still be disabled
+	db := database.GetDB()
+	if req.Conditions != nil || req.Actions != nil {
+		if !checkAutomationRule(c, db, rule.BoardID, rule.Conditions, rule.Actions) {
+			return
+		}
+	}
+
+	if err := db.Save(rule).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update automation"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(rule.BoardID, "automation_updated", rule)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAutomation deletes a rule and its execution log. Requires
+// manage_board.
+func (h *AutomationHandler) DeleteAutomation(c *gin.Context) {
+	rule, ok := loadBoardAutomation(c, "manage_board")
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&models.AutomationRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(rule).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete automation"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(rule.BoardID, "automation_deleted", gin.H{"id": rule.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Automation deleted successfully"})
+}
+
+// GetAutomationRuns returns a rule's execution log, newest first.
+func (h *AutomationHandler) GetAutomationRuns(c *gin.Context) {
+	rule, ok := loadBoardAutomation(c, "")
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > automationRunHistory {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(automationRunHistory)})
+			return
+		}
+		limit = n
+	}
+
+	runs := []models.AutomationRun{}
+	if err := database.GetDB().Where("rule_id = ?", rule.ID).Order("id desc").Limit(limit).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch automation runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// loadBoardAutomation loads the :ruleId rule of the :id board and checks that
+// the caller holds action on it, or only board access when action is empty.
+// It writes the error response and returns ok false on failure.
+func loadBoardAutomation(c *gin.Context, action string) (*models.AutomationRule, bool) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return nil, false
+	}
+	ruleID, err := strconv.ParseUint(c.Param("ruleId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid automation ID"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if action == "" {
+		if !hasAccessToBoard(uint(boardID), userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return nil, false
+		}
+	} else if !hasPermissionOnBoard(uint(boardID), userID, action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+
+	var rule models.AutomationRule
+	if err := database.GetDB().Where("id = ? AND board_id = ?", ruleID, boardID).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Automation not found"})
+		return nil, false
+	}
+	return &rule, true
+}
+
+// checkAutomationRule validates the conditions and actions of a rule against
+// the board. It writes the error response and returns false on failure.
+func checkAutomationRule(c *gin.Context, db *gorm.DB, boardID uint, conditions []models.AutomationCondition, actions []models.AutomationAction) bool {
+	for _, condition := range conditions {
+		operators, ok := automationOperators[condition.Field]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown condition field " + condition.Field})
+			return false
+		}
+		if !containsString(operators, condition.Operator) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Operator " + condition.Operator + " does not apply to " + condition.Field, "operators": operators})
+			return false
+		}
+		if condition.Field == "label_id" || condition.Field == "assignee_id" {
+			if condition.Operator == "contains" || condition.Operator == "not_contains" {
+				if _, err := strconv.ParseUint(condition.Value, 10, 32); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": condition.Field + " conditions take an ID as value"})
+					return false
+				}
+			}
+		}
+	}
+
+	for _, action := range actions {
+		switch action.Type {
+		case models.AutomationActionSetPriority:
+			if !containsString([]string{"low", "medium", "high"}, action.Value) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "set_priority takes low, medium or high"})
+				return false
+			}
+		case models.AutomationActionSetCategory:
+		case models.AutomationActionMoveTo:
+			if !checkTaskStatus(c, db, boardID, action.Value) {
+				return false
+			}
+		case models.AutomationActionAssign:
+			if len(action.UserIDs) == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "assign requires user_ids"})
+				return false
+			}
+			if !checkBoardMembers(c, db, boardID, uniqueUints(action.UserIDs), "assignee") {
+				return false
+			}
+		case models.AutomationActionUnassign:
+		case models.AutomationActionAddLabel, models.AutomationActionRemoveLabel:
+			if action.LabelID == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": action.Type + " requires label_id"})
+				return false
+			}
+			var count int64
+			if err := db.Model(&models.Label{}).Where("id = ? AND board_id = ?", *action.LabelID, boardID).Count(&count).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check labels"})
+				return false
+			}
+			if count == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errUnknownLabel.Error()})
+				return false
+			}
+		case models.AutomationActionFillActualHours:
+		case models.AutomationActionNotify:
+			if !containsString(automationNotifyTargets, action.Target) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "notify target must be owners, creator, assignees, watchers or users"})
+				return false
+			}
+			if action.Target == "users" {
+				if len(action.UserIDs) == 0 {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "notify users requires user_ids"})
+					return false
+				}
+				if !checkBoardMembers(c, db, boardID, uniqueUints(action.UserIDs), "notified user") {
+					return false
+				}
+			}
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown action " + action.Type})
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BoardTemplateHandler manages built-in and user-saved board templates.
+type BoardTemplateHandler struct{}
+
+func NewBoardTemplateHandler() *BoardTemplateHandler {
+	return &BoardTemplateHandler{}
+}
+
+// GetTemplates returns the built-in templates followed by the caller's own.
+func (h *BoardTemplateHandler) GetTemplates(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var templates []models.BoardTemplate
+	if err := database.GetDB().
+		Where("is_builtin = ? OR created_by = ?", true, userID).
+		Order("is_builtin desc, id asc").
+		Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch board templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func (h *BoardTemplateHandler) GetTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	template, err := findBoardTemplate(database.GetDB(), uint(templateID), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board template not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// SaveBoardAsTemplate captures a board's columns, labels and membership
+// settings as a template owned by the caller.
+func (h *BoardTemplateHandler) SaveBoardAsTemplate(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.SaveBoardTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	var columns []models.Column
+	if err := db.Where("board_id = ?", boardID).Order("position asc").Find(&columns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+		return
+	}
+
+	var labels []models.Label
+	if err := db.Where("board_id = ?", boardID).Order("LOWER(name) asc").Find(&labels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch labels"})
+		return
+	}
+
+	var settings models.BoardSettings
+	db.Where("board_id = ?", boardID).First(&settings)
+
+	template := models.BoardTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   &userID,
+		Columns:     []models.TemplateColumn{},
+		Labels:      []models.TemplateLabel{},
+		Settings: models.TemplateSettings{
+			AllowGuestAccess:             settings.AllowGuestAccess,
+			RequireApprovalForNewMembers: settings.RequireApprovalForNewMembers,
+			DefaultMemberRole:            settings.DefaultMemberRole,
+		},
+	}
+	for _, column := range columns {
+		template.Columns = append(template.Columns, models.TemplateColumn{
+			Title:    column.Title,
+			Status:   column.Status,
+			Color:    column.Color,
+			WIPLimit: column.WIPLimit,
+		})
+	}
+	for _, label := range labels {
+		template.Labels = append(template.Labels, models.TemplateLabel{Name: label.Name, Color: label.Color})
+	}
+
+	if err := db.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save board template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// DeleteTemplate removes one of the caller's templates. Built-in templates
+// cannot be deleted.
+func (h *BoardTemplateHandler) DeleteTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+
+	var template models.BoardTemplate
+	if err := database.GetDB().Where("id = ? AND created_by = ? AND is_builtin = ?", templateID, userID, false).First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board template not found"})
+		return
+	}
+
+	if err := database.GetDB().Delete(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete board template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Board template deleted successfully"})
+}
+
+// InitializeDefaults creates or refreshes the built-in templates by name.
+func (h *BoardTemplateHandler) InitializeDefaults() error {
+	db := database.GetDB()
+	for _, builtin := range models.BuiltinBoardTemplates {
+		var template models.BoardTemplate
+		err := db.Where("name = ? AND is_builtin = ?", builtin.Name, true).First(&template).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		template.Name = builtin.Name
+		template.Description = builtin.Description
+		template.IsBuiltin = true
+		template.Columns = builtin.Columns
+		template.Labels = builtin.Labels
+		template.Settings = builtin.Settings
+		if err := db.Save(&template).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findBoardTemplate loads a template the user may apply: any built-in one or
+// one they saved themselves.
+func findBoardTemplate(db *gorm.DB, templateID, userID uint) (*models.BoardTemplate, error) {
+	var template models.BoardTemplate
+	if err := db.Where("id = ? AND (is_builtin = ? OR created_by = ?)", templateID, true, userID).First(&template).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// applyBoardTemplate creates the template's columns and any of its labels the
+// board does not have yet, and returns the columns.
+func applyBoardTemplate(tx *gorm.DB, boardID uint, template *models.BoardTemplate) ([]models.Column, error) {
+	columns := make([]models.Column, 0, len(template.Columns))
+	for i, tc := range template.Columns {
+		column := models.Column{
+			BoardID:  boardID,
+			Title:    tc.Title,
+			Status:   tc.Status,
+			Color:    tc.Color,
+			Position: i + 1,
+			WIPLimit: tc.WIPLimit,
+		}
+		if err := tx.Create(&column).Error; err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	for _, tl := range template.Labels {
+		if taken, err := labelNameTaken(tx, boardID, tl.Name, 0); err != nil {
+			return nil, err
+		} else if taken {
+			continue
+		}
+		label := models.Label{BoardID: boardID, Name: tl.Name, Color: tl.Color}
+		if label.Color == "" {
+			label.Color = models.DefaultLabelColor
+		}
+		if err := tx.Create(&label).Error; err != nil {
+			return nil, err
+		}
+	}
+	return columns, nil
+}
@@ -3,11 +3,14 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"kanban-backend/internal/database"
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/mail"
 	"kanban-backend/internal/middleware"
 	"kanban-backend/internal/models"
 	"kanban-backend/internal/websocket"
@@ -16,11 +19,12 @@ import (
 )
 
 type BoardHandler struct {
-	hub *websocket.Hub
+	hub    *websocket.Hub
+	mailer mail.Mailer
 }
 
-func NewBoardHandler(hub *websocket.Hub) *BoardHandler {
-	return &BoardHandler{hub: hub}
+func NewBoardHandler(hub *websocket.Hub, mailer mail.Mailer) *BoardHandler {
+	return &BoardHandler{hub: hub, mailer: mailer}
 }
 
 func (h *BoardHandler) CreateBoard(c *gin.Context) {
@@ -32,6 +36,17 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 	}
 
 	db := database.GetDB()
+
+	template := models.BuiltinBoardTemplates[0]
+	if req.TemplateID != nil {
+		found, err := findBoardTemplate(db, *req.TemplateID, userID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board template not found"})
+			return
+		}
+		template = *found
+	}
+
 	tx := db.Begin()
 
 	// Create board
@@ -49,8 +64,13 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 
 	// Create board settings
 	settings := models.BoardSettings{
-		BoardID:           board.ID,
-		DefaultMemberRole: "member",
+		BoardID:                      board.ID,
+		AllowGuestAccess:             template.Settings.AllowGuestAccess,
+		RequireApprovalForNewMembers: template.Settings.RequireApprovalForNewMembers,
+		DefaultMemberRole:            template.Settings.DefaultMemberRole,
+	}
+	if settings.DefaultMemberRole == "" {
+		settings.DefaultMemberRole = "member"
 	}
 
 	if err := tx.Create(&settings).Error; err != nil {
@@ -59,6 +79,13 @@ func (h *BoardHandler) CreateBoard(c *gin.Context) {
 		return
 	}
 
+	// Seed columns from the template
+	if _, err := applyBoardTemplate(tx, board.ID, &template); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create board columns"})
+		return
+	}
+
 	// Add creator as owner
 	member := models.BoardMember{
 		BoardID:  board.ID,
@@ -107,6 +134,7 @@ func (h *BoardHandler) GetBoards(c *gin.Context) {
 	if err := database.GetDB().
 		Joins("JOIN board_members ON boards.id = board_members.board_id").
 		Where("board_members.user_id = ?", userID).
+		Where(twoFactorMet, true).
 		Find(&boards).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch boards"})
 		return
@@ -122,6 +150,24 @@ func (h *BoardHandler) GetBoards(c *gin.Context) {
 	c.JSON(http.StatusOK, boardResponses)
 }
 
+// ConnectWebSocket subscribes a board member to the board's live events.
+func (h *BoardHandler) ConnectWebSocket(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	c.Set("board_id", uint(boardID))
+	h.hub.HandleWebSocket(c)
+}
+
 func (h *BoardHandler) GetBoard(c *gin.Context) {
 	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -186,6 +232,90 @@ func (h *BoardHandler) UpdateBoard(c *gin.Context) {
 	c.JSON(http.StatusOK, boardResponse)
 }
 
+// UpdateSettings changes board-wide settings such as membership defaults and
+// how WIP limits are enforced. LLM settings have their own endpoint.
+func (h *BoardHandler) UpdateSettings(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.UpdateBoardSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var settings models.BoardSettings
+	if err := database.GetDB().Where("board_id = ?", boardID).First(&settings).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board settings not found"})
+		return
+	}
+
+	if req.AllowGuestAccess != nil {
+		settings.AllowGuestAccess = *req.AllowGuestAccess
+	}
+	if req.RequireApprovalForNewMembers != nil {
+		settings.RequireApprovalForNewMembers = *req.RequireApprovalForNewMembers
+	}
+	if req.DefaultMemberRole != nil {
+		settings.DefaultMemberRole = *req.DefaultMemberRole
+	}
+	if req.WIPEnforcement != nil {
+		settings.WIPEnforcement = *req.WIPEnforcement
+	}
+	if req.BlockDoneWithOpenSubtasks != nil {
+		settings.BlockDoneWithOpenSubtasks = *req.BlockDoneWithOpenSubtasks
+	}
+	if req.BlockStartWithOpenBlockers != nil {
+		settings.BlockStartWithOpenBlockers = *req.BlockStartWithOpenBlockers
+	}
+	if req.RequireTwoFactor != nil && *req.RequireTwoFactor != settings.RequireTwoFactor {
+		var board models.Board
+		if err := database.GetDB().First(&board, boardID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Board not found"})
+			return
+		}
+		if board.CreatedBy != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only board owner can change the two-factor requirement"})
+			return
+		}
+		// Owners would otherwise lock themselves out of their own board
+		if *req.RequireTwoFactor {
+			twoFactor, err := enabledTwoFactor(database.GetDB(), userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update board settings"})
+				return
+			}
+			if twoFactor == nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Enable two-factor authentication on your account first"})
+				return
+			}
+		}
+		settings.RequireTwoFactor = *req.RequireTwoFactor
+	}
+
+	if err := database.GetDB().Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update board settings"})
+		return
+	}
+
+	var boardResponse models.BoardResponse
+	h.loadBoardResponse(uint(boardID), &boardResponse)
+
+	// Broadcast update to all board members
+	h.hub.BroadcastToBoard(uint(boardID), "board_updated", boardResponse)
+
+	c.JSON(http.StatusOK, boardResponse)
+}
+
 func (h *BoardHandler) DeleteBoard(c *gin.Context) {
 	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -269,9 +399,35 @@ func (h *BoardHandler) InviteUser(c *gin.Context) {
 		return
 	}
 
+	// The invitation stands even if the email does not go out; invitees
+	// also find it in their invitation list
+	if err := h.sendInvitationEmail(&invitation); err != nil {
+		logger.Log.Errorf("Failed to send invitation %d: %v", invitation.ID, err)
+	}
+
 	c.JSON(http.StatusCreated, invitation)
 }
 
+func (h *BoardHandler) sendInvitationEmail(invitation *models.Invitation) error {
+	var board models.Board
+	if err := database.GetDB().First(&board, invitation.BoardID).Error; err != nil {
+		return err
+	}
+	var inviter models.User
+	if err := database.GetDB().First(&inviter, invitation.InvitedBy).Error; err != nil {
+		return err
+	}
+
+	return h.mailer.Send(mail.Message{
+		To:      invitation.InvitedEmail,
+		Subject: fmt.Sprintf("%s invited you to %s", inviter.Name, board.Title),
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to join the board \"%s\" as %s.\n\n"+
+			"Sign in or create an account with this email address to accept the invitation:\n\n%s\n\n"+
+			"The invitation expires on %s.\n",
+			inviter.Name, board.Title, invitation.Role, mail.AppURL(), invitation.ExpiresAt.UTC().Format("January 2, 2006")),
+	})
+}
+
 func (h *BoardHandler) GetInvitations(c *gin.Context) {
 	userEmail := middleware.GetUserEmail(c)
 
@@ -508,7 +664,11 @@ func (h *BoardHandler) UpdateMemberRole(c *gin.Context) {
 // Helper functions
 func (h *BoardHandler) hasAccess(boardID, userID uint) bool {
 	var count int64
-	database.GetDB().Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&count)
+	database.GetDB().Model(&models.BoardMember{}).
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
+		Where("board_members.board_id = ? AND board_members.user_id = ?", boardID, userID).
+		Where(twoFactorMet, true).
+		Count(&count)
 	return count > 0
 }
 
@@ -516,7 +676,9 @@ func (h *BoardHandler) hasPermission(boardID, userID uint, action string) bool {
 	var count int64
 	database.GetDB().Table("board_members").
 		Joins("JOIN member_permissions ON board_members.id = member_permissions.member_id").
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
 		Where("board_members.board_id = ? AND board_members.user_id = ? AND member_permissions.action = ? AND member_permissions.granted = ?", boardID, userID, action, true).
+		Where(twoFactorMet, true).
 		Count(&count)
 	return count > 0
 }
@@ -370,7 +370,11 @@ func (h *ChatHandler) DeleteMessage(c *gin.Context) {
 // Helper functions
 func (h *ChatHandler) hasAccess(boardID, userID uint) bool {
 	var count int64
-	database.GetDB().Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&count)
+	database.GetDB().Model(&models.BoardMember{}).
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
+		Where("board_members.board_id = ? AND board_members.user_id = ?", boardID, userID).
+		Where(twoFactorMet, true).
+		Count(&count)
 	return count > 0
 }
 
@@ -378,7 +382,9 @@ func (h *ChatHandler) hasPermission(boardID, userID uint, action string) bool {
 	var count int64
 	database.GetDB().Table("board_members").
 		Joins("JOIN member_permissions ON board_members.id = member_permissions.member_id").
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
 		Where("board_members.board_id = ? AND board_members.user_id = ? AND member_permissions.action = ? AND member_permissions.granted = ?", boardID, userID, action, true).
+		Where(twoFactorMet, true).
 		Count(&count)
 	return count > 0
 } 
\ No newline at end of file
@@ -3,19 +3,24 @@ package handlers
 import (
     "net/http"
     "strconv"
+    "strings"
 
     "kanban-backend/internal/database"
     "kanban-backend/internal/middleware"
     "kanban-backend/internal/models"
+    "kanban-backend/internal/websocket"
 
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
 )
 
 // ColumnHandler handles CRUD operations for board columns.
-type ColumnHandler struct{}
+type ColumnHandler struct {
+    hub *websocket.Hub
+}
 
-func NewColumnHandler() *ColumnHandler {
-    return &ColumnHandler{}
+func NewColumnHandler(hub *websocket.Hub) *ColumnHandler {
+    return &ColumnHandler{hub: hub}
 }
 
 // GetColumns returns all columns for a board, ordered by position.
@@ -61,6 +66,22 @@ func (h *ColumnHandler) CreateColumn(c *gin.Context) {
         return
     }
 
+    status := slugify(req.Status)
+    if status == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Status must contain letters or digits"})
+        return
+    }
+
+    var existing int64
+    if err := database.GetDB().Model(&models.Column{}).Where("board_id = ? AND status = ?", boardID, status).Count(&existing).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create column"})
+        return
+    }
+    if existing > 0 {
+        c.JSON(http.StatusConflict, gin.H{"error": "A column with status '" + status + "' already exists on this board"})
+        return
+    }
+
     // Determine next position
     var maxPos int
     database.GetDB().Model(&models.Column{}).Where("board_id = ?", boardID).Select("COALESCE(MAX(position),0)").Scan(&maxPos)
@@ -68,23 +89,426 @@ func (h *ColumnHandler) CreateColumn(c *gin.Context) {
     column := models.Column{
         BoardID:  uint(boardID),
         Title:    req.Title,
-        Status:   req.Status,
+        Status:   status,
         Color:    req.Color,
         Position: maxPos + 1,
+        WIPLimit: req.WIPLimit,
     }
 
     if err := database.GetDB().Create(&column).Error; err != nil {
+        // A concurrent create may have taken the status since the check above
+        if database.GetDB().Model(&models.Column{}).Where("board_id = ? AND status = ?", boardID, status).Count(&existing); existing > 0 {
+            c.JSON(http.StatusConflict, gin.H{"error": "A column with status '" + status + "' already exists on this board"})
+            return
+        }
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create column"})
         return
     }
 
+    // Broadcast to board members
+    h.hub.BroadcastToBoard(uint(boardID), "column_created", column)
+
     c.JSON(http.StatusCreated, column)
 }
 
+// UpdateColumn renames or recolors a column or changes its WIP limit. The
+// status key is left untouched so tasks stay attached to the column.
+func (h *ColumnHandler) UpdateColumn(c *gin.Context) {
+    boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+        return
+    }
+
+    columnID, err := strconv.ParseUint(c.Param("colId"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column ID"})
+        return
+    }
+
+    userID := middleware.GetUserID(c)
+    if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+        return
+    }
+
+    var req models.UpdateColumnRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    var column models.Column
+    if err := database.GetDB().Where("id = ? AND board_id = ?", columnID, boardID).First(&column).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Column not found"})
+        return
+    }
+
+    if req.Title != nil {
+        column.Title = *req.Title
+    }
+    if req.Color != nil {
+        column.Color = *req.Color
+    }
+    if req.WIPLimit != nil {
+        if *req.WIPLimit == 0 {
+            column.WIPLimit = nil
+        } else {
+            column.WIPLimit = req.WIPLimit
+        }
+    }
+
+    if err := database.GetDB().Save(&column).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update column"})
+        return
+    }
+
+    // Broadcast to board members
+    h.hub.BroadcastToBoard(uint(boardID), "column_updated", column)
+
+    c.JSON(http.StatusOK, column)
+}
+
+// ReorderColumns rewrites the position of every column on the board in one
+// transaction. The request must list each of the board's columns exactly once.
+func (h *ColumnHandler) ReorderColumns(c *gin.Context) {
+    boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+        return
+    }
+
+    userID := middleware.GetUserID(c)
+    if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+        return
+    }
+
+    var req models.ReorderColumnsRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    db := database.GetDB()
+    tx := db.Begin()
+
+    var columns []models.Column
+    if err := tx.Where("board_id = ?", boardID).Find(&columns).Error; err != nil {
+        tx.Rollback()
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+        return
+    }
+
+    byID := make(map[uint]*models.Column, len(columns))
+    for i := range columns {
+        byID[columns[i].ID] = &columns[i]
+    }
+
+    seen := make(map[uint]bool, len(req.ColumnIDs))
+    for _, id := range req.ColumnIDs {
+        if byID[id] == nil || seen[id] {
+            tx.Rollback()
+            c.JSON(http.StatusBadRequest, gin.H{"error": "column_ids must list each column of the board exactly once"})
+            return
+        }
+        seen[id] = true
+    }
+    if len(seen) != len(columns) {
+        tx.Rollback()
+        c.JSON(http.StatusBadRequest, gin.H{"error": "column_ids must list each column of the board exactly once"})
+        return
+    }
+
+    ordered := make([]models.Column, 0, len(req.ColumnIDs))
+    for i, id := range req.ColumnIDs {
+        column := byID[id]
+        column.Position = i + 1
+        if err := tx.Model(column).UpdateColumn("position", column.Position).Error; err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder columns"})
+            return
+        }
+        ordered = append(ordered, *column)
+    }
+
+    tx.Commit()
+
+    // Broadcast to board members
+    h.hub.BroadcastToBoard(uint(boardID), "columns_reordered", ordered)
+
+    c.JSON(http.StatusOK, ordered)
+}
+
+// DeleteColumn removes a column after moving its tasks to the column given by
+// the target_column_id query parameter. Both happen in one transaction.
+func (h *ColumnHandler) DeleteColumn(c *gin.Context) {
+    boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+        return
+    }
+
+    columnID, err := strconv.ParseUint(c.Param("colId"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column ID"})
+        return
+    }
+
+    targetID, err := strconv.ParseUint(c.Query("target_column_id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "target_column_id is required"})
+        return
+    }
+    if targetID == columnID {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Target column must differ from the deleted column"})
+        return
+    }
+
+    userID := middleware.GetUserID(c)
+    if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+        return
+    }
+
+    db := database.GetDB()
+
+    var column, target models.Column
+    if err := db.Where("id = ? AND board_id = ?", columnID, boardID).First(&column).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Column not found"})
+        return
+    }
+    if err := db.Where("id = ? AND board_id = ?", targetID, boardID).First(&target).Error; err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Target column not found on this board"})
+        return
+    }
+
+    tx := db.Begin()
+
+    // Append the migrated tasks to the end of the target column, keeping their order
+    var tasks []models.Task
+    if err := tx.Where("board_id = ? AND status = ?", boardID, column.Status).Order("position asc, id asc").Find(&tasks).Error; err != nil {
+        tx.Rollback()
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch column tasks"})
+        return
+    }
+
+    position, err := nextTaskPosition(tx, uint(boardID), target.Status, 0)
+    if err != nil {
+        tx.Rollback()
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move column tasks"})
+        return
+    }
+
+    movedTaskIDs := make([]uint, 0, len(tasks))
+    for _, task := range tasks {
+        if err := moveTaskInTx(tx, &task, target.Status, position, userID); err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move column tasks"})
+            return
+        }
+        movedTaskIDs = append(movedTaskIDs, task.ID)
+        position += taskPositionGap
+    }
+
+    if err := tx.Delete(&column).Error; err != nil {
+        tx.Rollback()
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete column"})
+        return
+    }
+
+    tx.Commit()
+
+    payload := gin.H{
+        "column_id":        column.ID,
+        "target_column_id": target.ID,
+        "target_status":    target.Status,
+        "moved_task_ids":   movedTaskIDs,
+    }
+
+    // Broadcast to board members
+    h.hub.BroadcastToBoard(uint(boardID), "column_deleted", payload)
+
+    c.JSON(http.StatusOK, payload)
+}
+
+// GetOrphanedStatuses lists task statuses on the board that match none of
+// its columns, together with the statuses tasks may be mapped onto.
+func (h *ColumnHandler) GetOrphanedStatuses(c *gin.Context) {
+    boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+        return
+    }
+
+    userID := middleware.GetUserID(c)
+    if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+        return
+    }
+
+    db := database.GetDB()
+    statuses, err := boardStatuses(db, uint(boardID))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+        return
+    }
+
+    orphaned := []models.OrphanedStatus{}
+    if err := db.Model(&models.Task{}).
+        Select("status, COUNT(*) AS task_count").
+        Where("board_id = ? AND status NOT IN ?", boardID, statuses).
+        Group("status").
+        Order("status asc").
+        Scan(&orphaned).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task statuses"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "orphaned":         orphaned,
+        "allowed_statuses": statuses,
+    })
+}
+
+// RepairStatuses moves the tasks of each orphaned status onto the mapped
+// column, appending them after the tasks already there.
+func (h *ColumnHandler) RepairStatuses(c *gin.Context) {
+    boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+        return
+    }
+
+    userID := middleware.GetUserID(c)
+    if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+        c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+        return
+    }
+
+    var req models.RepairStatusesRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    db := database.GetDB()
+    statuses, err := boardStatuses(db, uint(boardID))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+        return
+    }
+
+    for from, to := range req.Mappings {
+        if containsStatus(statuses, from) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Status is not orphaned", "status": from})
+            return
+        }
+        if !containsStatus(statuses, to) {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown status for this board", "status": to, "allowed_statuses": statuses})
+            return
+        }
+    }
+
+    tx := db.Begin()
+
+    movedTaskIDs := []uint{}
+    for from, to := range req.Mappings {
+        var tasks []models.Task
+        if err := tx.Where("board_id = ? AND status = ?", boardID, from).Order("position asc, id asc").Find(&tasks).Error; err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+            return
+        }
+
+        position, err := nextTaskPosition(tx, uint(boardID), to, 0)
+        if err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to repair tasks"})
+            return
+        }
+
+        for _, task := range tasks {
+            if err := moveTaskInTx(tx, &task, to, position, userID); err != nil {
+                tx.Rollback()
+                c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to repair tasks"})
+                return
+            }
+            movedTaskIDs = append(movedTaskIDs, task.ID)
+            position += taskPositionGap
+        }
+    }
+
+    tx.Commit()
+
+    payload := gin.H{
+        "mappings":       req.Mappings,
+        "moved_task_ids": movedTaskIDs,
+    }
+
+    // Broadcast to board members
+    h.hub.BroadcastToBoard(uint(boardID), "task_statuses_repaired", payload)
+
+    c.JSON(http.StatusOK, payload)
+}
+
+// moveTaskInTx sets a task's status and rank as part of a bulk column change
+// and records the move in its audit trail.
+func moveTaskInTx(tx *gorm.DB, task *models.Task, status string, position float64, actorID uint) error {
+    before := taskFields(task, nil, nil)
+    if err := tx.Model(task).Updates(map[string]interface{}{"status": status, "position": position}).Error; err != nil {
+        return err
+    }
+    changes := diffTaskFields(before, taskFields(task, nil, nil))
+    return recordTaskActivity(tx, task, actorID, models.TaskActionMoved, models.ActivitySourceREST, changes)
+}
+
+// boardStatuses returns the statuses a task on the board may take: the
+// board's column statuses, or the default set while it has no columns.
+func boardStatuses(db *gorm.DB, boardID uint) ([]string, error) {
+    var statuses []string
+    if err := db.Model(&models.Column{}).Where("board_id = ?", boardID).Order("position asc").Pluck("status", &statuses).Error; err != nil {
+        return nil, err
+    }
+    if len(statuses) == 0 {
+        for _, column := range models.BuiltinBoardTemplates[0].Columns {
+            statuses = append(statuses, column.Status)
+        }
+    }
+    return statuses, nil
+}
+
+func containsStatus(statuses []string, status string) bool {
+    for _, s := range statuses {
+        if s == status {
+            return true
+        }
+    }
+    return false
+}
+
+// slugify turns a title into a column status key: lowercase, dashes for
+// spaces and nothing but a-z, 0-9 and dashes. It is empty when nothing is left.
+func slugify(s string) string {
+    s = strings.ToLower(strings.TrimSpace(s))
+    s = strings.ReplaceAll(s, " ", "-")
+    var b strings.Builder
+    for _, r := range s {
+        if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+            b.WriteRune(r)
+        }
+    }
+    return b.String()
+}
+
 // helper reuse existing permission functions from board handler
 func hasAccessToBoard(boardID, userID uint) bool {
     var count int64
-    database.GetDB().Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&count)
+    database.GetDB().Model(&models.BoardMember{}).
+        Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
+        Where("board_members.board_id = ? AND board_members.user_id = ?", boardID, userID).
+        Where(twoFactorMet, true).
+        Count(&count)
     return count > 0
 }
 
@@ -92,7 +516,9 @@ func hasPermissionOnBoard(boardID, userID uint, action string) bool {
     var count int64
     database.GetDB().Table("board_members").
         Joins("JOIN member_permissions ON board_members.id = member_permissions.member_id").
+        Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
         Where("board_members.board_id = ? AND board_members.user_id = ? AND member_permissions.action = ? AND member_permissions.granted = ?", boardID, userID, action, true).
+        Where(twoFactorMet, true).
         Count(&count)
     return count > 0
 }
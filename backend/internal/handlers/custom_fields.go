@@ -0,0 +1,633 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxCustomFieldTextLength caps the length of text field values.
+const maxCustomFieldTextLength = 1000
+
+// customFieldDateLayout is how date field values are accepted and returned.
+const customFieldDateLayout = "2006-01-02"
+
+type CustomFieldHandler struct {
+	hub *websocket.Hub
+}
+
+func NewCustomFieldHandler(hub *websocket.Hub) *CustomFieldHandler {
+	return &CustomFieldHandler{hub: hub}
+}
+
+// GetCustomFields returns the custom fields of a board in display order.
+func (h *CustomFieldHandler) GetCustomFields(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	fields, err := boardCustomFields(database.GetDB(), uint(boardID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch custom fields"})
+		return
+	}
+
+	c.JSON(http.StatusOK, fields)
+}
+
+// CreateCustomField adds a field to the end of a board's fields. Requires
+// manage_board; names must be unique on the board regardless of case.
+func (h *CustomFieldHandler) CreateCustomField(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	name, ok := checkCustomFieldName(c, db, uint(boardID), req.Name, 0)
+	if !ok {
+		return
+	}
+	options, ok := checkCustomFieldOptions(c, req.Type, req.Options)
+	if !ok {
+		return
+	}
+
+	var maxPosition int
+	if err := db.Model(&models.CustomFieldDefinition{}).
+		Where("board_id = ?", boardID).
+		Select("COALESCE(MAX(position),0)").
+		Scan(&maxPosition).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create custom field"})
+		return
+	}
+
+	field := models.CustomFieldDefinition{
+		BoardID:  uint(boardID),
+		Name:     name,
+		Type:     req.Type,
+		Options:  options,
+		Required: req.Required,
+		Position: maxPosition + 1,
+	}
+	if err := db.Create(&field).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create custom field"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(field.BoardID, "custom_field_created", field)
+
+	c.JSON(http.StatusCreated, field)
+}
+
+// UpdateCustomField renames, reorders or changes the options of a field.
+// Options that tasks still use cannot be removed. Requires manage_board.
+func (h *CustomFieldHandler) UpdateCustomField(c *gin.Context) {
+	field, ok := loadBoardCustomField(c, "manage_board")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	if req.Name != nil {
+		name, ok := checkCustomFieldName(c, db, field.BoardID, *req.Name, field.ID)
+		if !ok {
+			return
+		}
+		field.Name = name
+	}
+
+	if req.Options != nil {
+		options, ok := checkCustomFieldOptions(c, field.Type, req.Options)
+		if !ok {
+			return
+		}
+		removed := []string{}
+		for _, option := range field.Options {
+			if !containsString(options, option) {
+				removed = append(removed, option)
+			}
+		}
+		if len(removed) > 0 {
+			var inUse int64
+			if err := db.Model(&models.TaskCustomFieldValue{}).
+				Where("field_id = ? AND text_value IN ?", field.ID, removed).
+				Count(&inUse).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom field"})
+				return
+			}
+			if inUse > 0 {
+				c.JSON(http.StatusConflict, gin.H{"error": "Options still set on tasks cannot be removed", "options": removed})
+				return
+			}
+		}
+		field.Options = options
+	}
+
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if req.Position != nil {
+		field.Position = *req.Position
+	}
+
+	if err := db.Save(field).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update custom field"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(field.BoardID, "custom_field_updated", field)
+
+	c.JSON(http.StatusOK, field)
+}
+
+// DeleteCustomField removes a field and its value on every task. Requires
+// manage_board.
+func (h *CustomFieldHandler) DeleteCustomField(c *gin.Context) {
+	field, ok := loadBoardCustomField(c, "manage_board")
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("field_id = ?", field.ID).Delete(&models.TaskCustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(field).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete custom field"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(field.BoardID, "custom_field_deleted", gin.H{"id": field.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field deleted successfully"})
+}
+
+// loadBoardCustomField loads the :fieldId field of the :id board and checks
+// that the caller holds action on it. It writes the error response and returns
+// ok false on failure.
+func loadBoardCustomField(c *gin.Context, action string) (*models.CustomFieldDefinition, bool) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return nil, false
+	}
+	fieldID, err := strconv.ParseUint(c.Param("fieldId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid custom field ID"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+
+	var field models.CustomFieldDefinition
+	if err := database.GetDB().Where("id = ? AND board_id = ?", fieldID, boardID).First(&field).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Custom field not found"})
+		return nil, false
+	}
+	return &field, true
+}
+
+// checkCustomFieldName trims name and checks that no other field on the board
+// uses it, ignoring case.
+func checkCustomFieldName(c *gin.Context, db *gorm.DB, boardID uint, name string, exceptID uint) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Custom field name cannot be empty"})
+		return "", false
+	}
+
+	var count int64
+	if err := db.Model(&models.CustomFieldDefinition{}).
+		Where("board_id = ? AND LOWER(name) = ? AND id <> ?", boardID, strings.ToLower(name), exceptID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check custom field name"})
+		return "", false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A custom field with this name already exists"})
+		return "", false
+	}
+	return name, true
+}
+
+// checkCustomFieldOptions validates the options of a field: select fields
+// need at least one distinct, non-empty option and other types take none.
+func checkCustomFieldOptions(c *gin.Context, fieldType string, options []string) ([]string, bool) {
+	if fieldType != models.CustomFieldSelect {
+		if len(options) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only select fields have options"})
+			return nil, false
+		}
+		return []string{}, true
+	}
+
+	cleaned := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Select options cannot be empty"})
+			return nil, false
+		}
+		if containsString(cleaned, option) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Select options must be unique"})
+			return nil, false
+		}
+		cleaned = append(cleaned, option)
+	}
+	if len(cleaned) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Select fields need at least one option"})
+		return nil, false
+	}
+	return cleaned, true
+}
+
+// boardCustomFields loads the custom fields of a board in display order.
+func boardCustomFields(db *gorm.DB, boardID uint) ([]models.CustomFieldDefinition, error) {
+	fields := []models.CustomFieldDefinition{}
+	err := db.Where("board_id = ?", boardID).Order("position asc, id asc").Find(&fields).Error
+	return fields, err
+}
+
+// resolveCustomFieldValues validates the custom field values of a task request
+// against the board's fields and converts them to rows; a nil row clears the
+// field. When creating, every required field must get a value. It writes a
+// 400 response and returns ok false when a value is invalid.
+func resolveCustomFieldValues(c *gin.Context, db *gorm.DB, boardID uint, values map[uint]interface{}, creating bool) ([]models.CustomFieldDefinition, map[uint]*models.TaskCustomFieldValue, bool) {
+	if len(values) == 0 && !creating {
+		return nil, nil, true
+	}
+
+	fields, err := boardCustomFields(db, boardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch custom fields"})
+		return nil, nil, false
+	}
+
+	known := make(map[uint]bool, len(fields))
+	for _, field := range fields {
+		known[field.ID] = true
+	}
+	for id := range values {
+		if !known[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Custom field not found on this board", "field_id": id})
+			return nil, nil, false
+		}
+	}
+
+	rows := make(map[uint]*models.TaskCustomFieldValue, len(values))
+	for i := range fields {
+		field := &fields[i]
+		raw, given := values[field.ID]
+		if !given {
+			if creating && field.Required {
+				c.JSON(http.StatusBadRequest, gin.H{"error": field.Name + " is required", "field_id": field.ID})
+				return nil, nil, false
+			}
+			continue
+		}
+
+		row, err := parseCustomFieldValue(field, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "field_id": field.ID})
+			return nil, nil, false
+		}
+		if row == nil && field.Required {
+			c.JSON(http.StatusBadRequest, gin.H{"error": field.Name + " is required", "field_id": field.ID})
+			return nil, nil, false
+		}
+		if row != nil && row.UserValue != nil {
+			var count int64
+			if err := db.Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, *row.UserValue).Count(&count).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check board members"})
+				return nil, nil, false
+			}
+			if count == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": field.Name + " must be a board member", "field_id": field.ID})
+				return nil, nil, false
+			}
+		}
+		rows[field.ID] = row
+	}
+
+	return fields, rows, true
+}
+
+// parseCustomFieldValue converts a JSON value to a row for field. Null and
+// empty strings clear the field and yield a nil row.
+func parseCustomFieldValue(field *models.CustomFieldDefinition, raw interface{}) (*models.TaskCustomFieldValue, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if s, ok := raw.(string); ok && strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	row := &models.TaskCustomFieldValue{FieldID: field.ID}
+	switch field.Type {
+	case models.CustomFieldText:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be text", field.Name)
+		}
+		if len(s) > maxCustomFieldTextLength {
+			return nil, fmt.Errorf("%s must be at most %d characters", field.Name, maxCustomFieldTextLength)
+		}
+		row.TextValue = &s
+	case models.CustomFieldNumber:
+		n, ok := raw.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a number", field.Name)
+		}
+		row.NumberValue = &n
+	case models.CustomFieldSelect:
+		s, ok := raw.(string)
+		if !ok || !containsString(field.Options, s) {
+			return nil, fmt.Errorf("%s must be one of: %s", field.Name, strings.Join(field.Options, ", "))
+		}
+		row.TextValue = &s
+	case models.CustomFieldDate:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a date", field.Name)
+		}
+		t, err := parseCustomFieldDate(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a date", field.Name)
+		}
+		row.DateValue = &t
+	case models.CustomFieldUser:
+		n, ok := raw.(float64)
+		if !ok || n <= 0 || n != math.Trunc(n) || n > math.MaxUint32 {
+			return nil, fmt.Errorf("%s must be a user ID", field.Name)
+		}
+		id := uint(n)
+		row.UserValue = &id
+	default:
+		return nil, errors.New("unsupported custom field type " + field.Type)
+	}
+	return row, nil
+}
+
+// parseCustomFieldDate accepts what parseDateParam does and keeps only the
+// calendar date, so date values compare equal in filters.
+func parseCustomFieldDate(value string) (time.Time, error) {
+	t, err := parseDateParam(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), nil
+}
+
+// customFieldValue returns the value of row as the API shows it for field.
+func customFieldValue(field *models.CustomFieldDefinition, row *models.TaskCustomFieldValue) interface{} {
+	if row == nil {
+		return nil
+	}
+	switch field.Type {
+	case models.CustomFieldNumber:
+		return derefFloat(row.NumberValue)
+	case models.CustomFieldDate:
+		if row.DateValue == nil {
+			return nil
+		}
+		return row.DateValue.UTC().Format(customFieldDateLayout)
+	case models.CustomFieldUser:
+		return derefUint(row.UserValue)
+	default:
+		if row.TextValue == nil {
+			return nil
+		}
+		return *row.TextValue
+	}
+}
+
+// setTaskCustomFields writes the values of a task request; fields without a
+// row are cleared and fields missing from rows are left alone.
+func setTaskCustomFields(tx *gorm.DB, taskID uint, rows map[uint]*models.TaskCustomFieldValue) error {
+	for fieldID, row := range rows {
+		if err := tx.Where("task_id = ? AND field_id = ?", taskID, fieldID).Delete(&models.TaskCustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		if row == nil {
+			continue
+		}
+		row.TaskID = taskID
+		if err := tx.Create(row).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskCustomFieldValues loads the custom field values of a batch of tasks,
+// keyed by task and then by field ID.
+func taskCustomFieldValues(db *gorm.DB, taskIDs []uint) (map[uint]map[uint]interface{}, error) {
+	var rows []models.TaskCustomFieldValue
+	if err := db.Where("task_id IN ?", taskIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[uint]map[uint]interface{})
+	if len(rows) == 0 {
+		return byTask, nil
+	}
+
+	fieldIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		fieldIDs = append(fieldIDs, row.FieldID)
+	}
+	var fields []models.CustomFieldDefinition
+	if err := db.Where("id IN ?", uniqueUints(fieldIDs)).Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	fieldByID := make(map[uint]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		fieldByID[fields[i].ID] = &fields[i]
+	}
+
+	for i := range rows {
+		field, ok := fieldByID[rows[i].FieldID]
+		if !ok {
+			continue
+		}
+		if byTask[rows[i].TaskID] == nil {
+			byTask[rows[i].TaskID] = make(map[uint]interface{})
+		}
+		byTask[rows[i].TaskID][field.ID] = customFieldValue(field, &rows[i])
+	}
+	return byTask, nil
+}
+
+// customFieldChanges lists the audited changes of writing rows over a task's
+// current values, one per changed field.
+func customFieldChanges(fields []models.CustomFieldDefinition, current map[uint]interface{}, rows map[uint]*models.TaskCustomFieldValue) []models.FieldChange {
+	changes := []models.FieldChange{}
+	for i := range fields {
+		row, given := rows[fields[i].ID]
+		if !given {
+			continue
+		}
+		value := customFieldValue(&fields[i], row)
+		if reflect.DeepEqual(current[fields[i].ID], value) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: "custom_fields." + fields[i].Name, Old: current[fields[i].ID], New: value})
+	}
+	return changes
+}
+
+// applyCustomFieldFilters narrows a task listing by custom field values:
+// cf[<field id>]=value matches a value ("none" for unset; text matches by
+// substring and select takes a comma list) and cf_min / cf_max bound number
+// and date fields.
+func applyCustomFieldFilters(c *gin.Context, db *gorm.DB, boardID uint, query *gorm.DB) (*gorm.DB, bool) {
+	equals, mins, maxes := c.QueryMap("cf"), c.QueryMap("cf_min"), c.QueryMap("cf_max")
+	if len(equals) == 0 && len(mins) == 0 && len(maxes) == 0 {
+		return query, true
+	}
+
+	fields, err := boardCustomFields(db, boardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch custom fields"})
+		return nil, false
+	}
+	fieldByKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		fieldByKey[strconv.FormatUint(uint64(fields[i].ID), 10)] = &fields[i]
+	}
+
+	const valueExists = "EXISTS (SELECT 1 FROM task_custom_field_values WHERE task_custom_field_values.task_id = tasks.id AND task_custom_field_values.field_id = ? AND "
+
+	for key, value := range equals {
+		field, ok := fieldByKey[key]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Custom field not found on this board", "field_id": key})
+			return nil, false
+		}
+		if value == "none" {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM task_custom_field_values WHERE task_custom_field_values.task_id = tasks.id AND task_custom_field_values.field_id = ?)", field.ID)
+			continue
+		}
+
+		switch field.Type {
+		case models.CustomFieldText:
+			query = query.Where(valueExists+"LOWER(task_custom_field_values.text_value) LIKE ?)", field.ID, "%"+strings.ToLower(value)+"%")
+		case models.CustomFieldSelect:
+			query = query.Where(valueExists+"task_custom_field_values.text_value IN ?)", field.ID, strings.Split(value, ","))
+		case models.CustomFieldNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number for " + field.Name})
+				return nil, false
+			}
+			query = query.Where(valueExists+"task_custom_field_values.number_value = ?)", field.ID, n)
+		case models.CustomFieldDate:
+			t, err := parseCustomFieldDate(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date for " + field.Name})
+				return nil, false
+			}
+			query = query.Where(valueExists+"task_custom_field_values.date_value = ?)", field.ID, t)
+		case models.CustomFieldUser:
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID for " + field.Name})
+				return nil, false
+			}
+			query = query.Where(valueExists+"task_custom_field_values.user_value = ?)", field.ID, id)
+		}
+	}
+
+	for _, bound := range []struct {
+		values map[string]string
+		op     string
+	}{{mins, ">="}, {maxes, "<="}} {
+		for key, value := range bound.values {
+			field, ok := fieldByKey[key]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Custom field not found on this board", "field_id": key})
+				return nil, false
+			}
+			switch field.Type {
+			case models.CustomFieldNumber:
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid number for " + field.Name})
+					return nil, false
+				}
+				query = query.Where(valueExists+"task_custom_field_values.number_value "+bound.op+" ?)", field.ID, n)
+			case models.CustomFieldDate:
+				t, err := parseCustomFieldDate(value)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date for " + field.Name})
+					return nil, false
+				}
+				query = query.Where(valueExists+"task_custom_field_values.date_value "+bound.op+" ?)", field.ID, t)
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "cf_min and cf_max only apply to number and date fields"})
+				return nil, false
+			}
+		}
+	}
+
+	return query, true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,481 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errUnknownLabel = errors.New("label not found on this board")
+
+type LabelHandler struct {
+	hub *websocket.Hub
+}
+
+func NewLabelHandler(hub *websocket.Hub) *LabelHandler {
+	return &LabelHandler{hub: hub}
+}
+
+// GetLabels returns a board's labels by name with the number of live tasks
+// using each.
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var labels []models.Label
+	if err := db.Where("board_id = ?", boardID).Order("LOWER(name) asc").Find(&labels).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch labels"})
+		return
+	}
+
+	var counts []struct {
+		LabelID uint
+		Count   int64
+	}
+	if err := db.Model(&models.TaskLabel{}).
+		Select("task_labels.label_id, COUNT(*) AS count").
+		Joins("JOIN tasks ON tasks.id = task_labels.task_id AND tasks.deleted_at IS NULL").
+		Where("tasks.board_id = ?", boardID).
+		Group("task_labels.label_id").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count labelled tasks"})
+		return
+	}
+	countByLabel := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		countByLabel[row.LabelID] = row.Count
+	}
+
+	responses := make([]models.LabelResponse, 0, len(labels))
+	for _, label := range labels {
+		responses = append(responses, models.LabelResponse{Label: label, TaskCount: countByLabel[label.ID]})
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateLabel adds a label to a board. Anyone who can edit tasks may create
+// labels; names must be unique on the board regardless of case.
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Label name cannot be empty"})
+		return
+	}
+	if taken, err := labelNameTaken(db, uint(boardID), name, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create label"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "A label with this name already exists"})
+		return
+	}
+
+	label := models.Label{
+		BoardID:     uint(boardID),
+		Name:        name,
+		Color:       req.Color,
+		Description: req.Description,
+	}
+	if label.Color == "" {
+		label.Color = models.DefaultLabelColor
+	}
+	if err := db.Create(&label).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create label"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(label.BoardID, "label_created", label)
+
+	c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel renames or recolors a label. Requires manage_board.
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	label, ok := loadBoardLabel(c, "manage_board")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Label name cannot be empty"})
+			return
+		}
+		if taken, err := labelNameTaken(db, label.BoardID, name, label.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update label"})
+			return
+		} else if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "A label with this name already exists"})
+			return
+		}
+		label.Name = name
+	}
+	if req.Color != nil {
+		label.Color = *req.Color
+	}
+	if req.Description != nil {
+		label.Description = *req.Description
+	}
+
+	if err := db.Save(label).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update label"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(label.BoardID, "label_updated", label)
+
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel removes a label from the board and from every task using it.
+// Requires manage_board.
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	label, ok := loadBoardLabel(c, "manage_board")
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(c)
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var taskIDs []uint
+		if err := tx.Model(&models.TaskLabel{}).Where("label_id = ?", label.ID).Pluck("task_id", &taskIDs).Error; err != nil {
+			return err
+		}
+		err := relabelTasks(tx, taskIDs, userID, func() error {
+			return tx.Where("label_id = ?", label.ID).Delete(&models.TaskLabel{}).Error
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Delete(label).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete label"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(label.BoardID, "label_deleted", gin.H{"id": label.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+// MergeLabels moves the tasks of the source labels to the target label and
+// deletes the sources. Requires manage_board.
+func (h *LabelHandler) MergeLabels(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.MergeLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if containsUint(req.SourceIDs, req.TargetID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A label cannot be merged into itself"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var target models.Label
+	if err := db.Where("id = ? AND board_id = ?", req.TargetID, boardID).First(&target).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target label not found"})
+		return
+	}
+
+	var sourceCount int64
+	if err := db.Model(&models.Label{}).Where("id IN ? AND board_id = ?", req.SourceIDs, boardID).Count(&sourceCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge labels"})
+		return
+	}
+	if int(sourceCount) != len(uniqueUints(req.SourceIDs)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source label not found"})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var taskIDs []uint
+		if err := tx.Model(&models.TaskLabel{}).Where("label_id IN ?", req.SourceIDs).Distinct().Pluck("task_id", &taskIDs).Error; err != nil {
+			return err
+		}
+		err := relabelTasks(tx, taskIDs, userID, func() error {
+			for _, taskID := range taskIDs {
+				taskLabel := models.TaskLabel{TaskID: taskID, LabelID: target.ID}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&taskLabel).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Where("label_id IN ?", req.SourceIDs).Delete(&models.TaskLabel{}).Error
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", req.SourceIDs).Delete(&models.Label{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge labels"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(target.BoardID, "labels_merged", gin.H{
+		"source_ids": req.SourceIDs,
+		"target":     target,
+	})
+
+	c.JSON(http.StatusOK, target)
+}
+
+// relabelTasks runs relabel, which changes the labels of tasks in tx, and
+// records the label change of each task as an update by actorID. updated_at
+// is bumped so clients syncing with updated_since pick the change up.
+func relabelTasks(tx *gorm.DB, taskIDs []uint, actorID uint, relabel func() error) error {
+	before := make(map[uint][]string, len(taskIDs))
+	for _, taskID := range taskIDs {
+		names, err := taskLabelNames(tx, taskID)
+		if err != nil {
+			return err
+		}
+		before[taskID] = names
+	}
+
+	if err := relabel(); err != nil {
+		return err
+	}
+
+	// Trashed tasks keep their labels for a restore, so they are recorded too
+	var tasks []models.Task
+	if len(taskIDs) > 0 {
+		if err := tx.Unscoped().Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	for i := range tasks {
+		task := &tasks[i]
+		after, err := taskLabelNames(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		changes := diffTaskFields(taskFields(task, before[task.ID], nil), taskFields(task, after, nil))
+		if len(changes) == 0 {
+			continue
+		}
+		if err := tx.Unscoped().Model(task).UpdateColumn("updated_at", now).Error; err != nil {
+			return err
+		}
+		if err := recordTaskActivity(tx, task, actorID, models.TaskActionUpdated, models.ActivitySourceREST, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadBoardLabel loads the :labelId label of the :id board and checks that the
+// caller holds action on it. It writes the error response and returns ok
+// false on failure.
+func loadBoardLabel(c *gin.Context, action string) (*models.Label, bool) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return nil, false
+	}
+	labelID, err := strconv.ParseUint(c.Param("labelId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid label ID"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+
+	var label models.Label
+	if err := database.GetDB().Where("id = ? AND board_id = ?", labelID, boardID).First(&label).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Label not found"})
+		return nil, false
+	}
+	return &label, true
+}
+
+// labelNameTaken reports whether another label on the board has name,
+// ignoring case.
+func labelNameTaken(db *gorm.DB, boardID uint, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Label{}).
+		Where("board_id = ? AND LOWER(name) = ? AND id <> ?", boardID, strings.ToLower(name), exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// resolveTaskLabels finds the labels a task request refers to by ID or by
+// name. Names without a label on the board create one; those are also
+// returned as created so they can be announced once tx commits.
+func resolveTaskLabels(tx *gorm.DB, boardID uint, ids []uint, names []string) ([]models.Label, []models.Label, error) {
+	labels := []models.Label{}
+	created := []models.Label{}
+	seen := make(map[uint]bool)
+
+	ids = uniqueUints(ids)
+	if len(ids) > 0 {
+		var found []models.Label
+		if err := tx.Where("id IN ? AND board_id = ?", ids, boardID).Find(&found).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(found) != len(ids) {
+			return nil, nil, errUnknownLabel
+		}
+		for _, label := range found {
+			seen[label.ID] = true
+			labels = append(labels, label)
+		}
+	}
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var label models.Label
+		err := tx.Where("board_id = ? AND LOWER(name) = ?", boardID, strings.ToLower(name)).First(&label).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			label = models.Label{BoardID: boardID, Name: name, Color: models.DefaultLabelColor}
+			if err := tx.Create(&label).Error; err != nil {
+				return nil, nil, err
+			}
+			created = append(created, label)
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if !seen[label.ID] {
+			seen[label.ID] = true
+			labels = append(labels, label)
+		}
+	}
+
+	return labels, created, nil
+}
+
+// applyTaskLabels resolves the labels of a task request and sets them on task
+// inside tx. It writes the error response and returns ok false on failure.
+func applyTaskLabels(c *gin.Context, tx *gorm.DB, task *models.Task, ids []uint, names []string) ([]models.Label, []models.Label, bool) {
+	labels, created, err := resolveTaskLabels(tx, task.BoardID, ids, names)
+	if errors.Is(err, errUnknownLabel) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	if err == nil {
+		err = setTaskLabels(tx, task.ID, labels)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task labels"})
+		return nil, nil, false
+	}
+	return labels, created, true
+}
+
+// setTaskLabels replaces the labels of a task.
+func setTaskLabels(tx *gorm.DB, taskID uint, labels []models.Label) error {
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskLabel{}).Error; err != nil {
+		return err
+	}
+	for _, label := range labels {
+		if err := tx.Create(&models.TaskLabel{TaskID: taskID, LabelID: label.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelNames returns the names of labels in sorted order.
+func labelNames(labels []models.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// taskLabelNames loads the sorted label names of a task.
+func taskLabelNames(db *gorm.DB, taskID uint) ([]string, error) {
+	var names []string
+	err := db.Model(&models.Label{}).
+		Joins("JOIN task_labels ON task_labels.label_id = labels.id").
+		Where("task_labels.task_id = ?", taskID).
+		Pluck("labels.name", &names).Error
+	sort.Strings(names)
+	return names, err
+}
+
+func uniqueUints(values []uint) []uint {
+	unique := make([]uint, 0, len(values))
+	for _, v := range values {
+		if !containsUint(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,7 +36,7 @@ func UpdateLLMConfig(c *gin.Context) {
 
 	// Check if user is board owner
 	var member models.BoardMember
-	if err := database.DB.Where("board_id = ? AND user_id = ? AND role = ?", boardID, userID, "owner").First(&member).Error; err != nil {
+	if err := database.DB.Where("board_id = ? AND user_id = ? AND role = ?", boardID, userID, "owner").Where(twoFactorMet, true).First(&member).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only board owners can configure LLM settings"})
 		return
 	}
@@ -83,7 +84,7 @@ func GetLLMConfig(c *gin.Context) {
 
 	// Check if user is a board member
 	var member models.BoardMember
-	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).First(&member).Error; err != nil {
+	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).Where(twoFactorMet, true).First(&member).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
@@ -126,7 +127,7 @@ func SearchLLMModels(c *gin.Context) {
 
 	// Check membership
 	var member models.BoardMember
-	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).First(&member).Error; err != nil {
+	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).Where(twoFactorMet, true).First(&member).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
@@ -286,13 +287,13 @@ func listOpenRouterModels(apiKey string) ([]ProviderModel, error) {
 }
 
 // GenerateTasks uses LLM to generate tasks based on project description and member capabilities
-func GenerateTasks(c *gin.Context) {
+func (h *TaskHandler) GenerateTasks(c *gin.Context) {
 	boardID := c.Param("id")
 	userID := c.GetUint("user_id")
 
 	// Check if user is board owner or admin
 	var member models.BoardMember
-	if err := database.DB.Where("board_id = ? AND user_id = ? AND role IN ?", boardID, userID, []string{"owner", "admin"}).First(&member).Error; err != nil {
+	if err := database.DB.Where("board_id = ? AND user_id = ? AND role IN ?", boardID, userID, []string{"owner", "admin"}).Where(twoFactorMet, true).First(&member).Error; err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only board owners and admins can generate tasks"})
 		return
 	}
@@ -353,22 +354,12 @@ func GenerateTasks(c *gin.Context) {
         }
     }
 
-    // Helper: slugify category into a status key used by columns
-    slugify := func(s string) string {
-        s = strings.ToLower(strings.TrimSpace(s))
-        s = strings.ReplaceAll(s, " ", "-")
-        // keep only a-z, 0-9 and '-'
-        var b strings.Builder
-        for _, r := range s {
-            if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
-                b.WriteRune(r)
-            }
-        }
-        out := b.String()
-        if out == "" {
-            out = "todo"
+    // Helper: status key of the column for a category
+    statusFor := func(category string) string {
+        if status := slugify(category); status != "" {
+            return status
         }
-        return out
+        return "todo"
     }
 
     // Begin transaction to create missing columns and tasks atomically
@@ -386,6 +377,17 @@ func GenerateTasks(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
         return
     }
+    // Boards created before column seeding get the default columns first so
+    // their existing tasks keep a column once category columns are added
+    if len(existingCols) == 0 {
+        seeded, err := applyBoardTemplate(tx, board.ID, &models.BuiltinBoardTemplates[0])
+        if err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create columns"})
+            return
+        }
+        existingCols = seeded
+    }
     statusToCol := make(map[string]models.Column)
     maxPos := 0
     for _, col := range existingCols {
@@ -403,7 +405,7 @@ func GenerateTasks(c *gin.Context) {
         if cat == "" {
             cat = "To Do"
         }
-        status := slugify(cat)
+        status := statusFor(cat)
         if _, ok := categories[status]; !ok {
             // Title-case first letter for display
             title := cat
@@ -432,14 +434,49 @@ func GenerateTasks(c *gin.Context) {
         }
     }
 
+    // Check WIP limits for every column receiving generated tasks
+    adding := make(map[string]int) // status -> number of generated tasks
+    for _, td := range tasks {
+        cat := strings.TrimSpace(td.Category)
+        if cat == "" {
+            cat = "To Do"
+        }
+        adding[statusFor(cat)]++
+    }
+    wipExceeded := []string{}
+    for status, n := range adding {
+        exceeded, ok := enforceWIPLimit(c, tx, board.ID, status, n)
+        if !ok {
+            tx.Rollback()
+            return
+        }
+        if exceeded {
+            wipExceeded = append(wipExceeded, status)
+        }
+    }
+    sort.Strings(wipExceeded)
+
     // Create tasks with status mapped to their category's slug/column
     createdTasks := []models.Task{}
+    automations := []*automationResult{}
+    nextPosition := make(map[string]float64) // status -> rank for the next appended task
     for _, taskData := range tasks {
         category := strings.TrimSpace(taskData.Category)
         if category == "" {
             category = "To Do"
         }
-        status := slugify(category)
+        status := statusFor(category)
+
+        position, ok := nextPosition[status]
+        if !ok {
+            var err error
+            if position, err = nextTaskPosition(tx, board.ID, status, 0); err != nil {
+                tx.Rollback()
+                c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create generated tasks"})
+                return
+            }
+        }
+        nextPosition[status] = position + taskPositionGap
 
         task := models.Task{
             Title:       taskData.Title,
@@ -447,15 +484,33 @@ func GenerateTasks(c *gin.Context) {
             Priority:    normalizePriority(taskData.Priority),
             Category:    category,
             Status:      status,
+            Position:    position,
             BoardID:     board.ID,
             CreatedBy:   userID,
         }
 
-        // Find assignee by exact name or email (case-insensitive)
-        if taskData.AssigneeName != "" {
+        // Proposed dates are optional; unparseable or inverted ones are dropped
+        if d, err := time.Parse("2006-01-02", strings.TrimSpace(taskData.DueDate)); err == nil {
+            task.DueDate = &d
+        }
+        if d, err := time.Parse("2006-01-02", strings.TrimSpace(taskData.StartDate)); err == nil {
+            if task.DueDate == nil || !d.After(*task.DueDate) {
+                task.StartDate = &d
+            }
+        }
+
+        // Find assignees by exact name or email (case-insensitive)
+        assigneeIDs := []uint{}
+        for _, name := range append([]string{taskData.AssigneeName}, taskData.AssigneeNames...) {
+            name = strings.TrimSpace(name)
+            if name == "" {
+                continue
+            }
             for _, m := range members {
-                if strings.EqualFold(m.User.Name, taskData.AssigneeName) || strings.EqualFold(m.User.Email, taskData.AssigneeName) {
-                    task.AssigneeID = &m.UserID
+                if strings.EqualFold(m.User.Name, name) || strings.EqualFold(m.User.Email, name) {
+                    if !containsUint(assigneeIDs, m.UserID) {
+                        assigneeIDs = append(assigneeIDs, m.UserID)
+                    }
                     break
                 }
             }
@@ -466,6 +521,24 @@ func GenerateTasks(c *gin.Context) {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create generated tasks"})
             return
         }
+        if err := setTaskAssignees(tx, &task, assigneeIDs); err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign generated tasks"})
+            return
+        }
+        changes := snapshotTaskFields(taskFields(&task, nil, sortedUints(assigneeIDs)), true)
+        if err := recordTaskActivity(tx, &task, userID, models.TaskActionCreated, models.ActivitySourceLLM, changes); err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+            return
+        }
+        taskAutomations, err := runAutomations(tx, &task, userID, models.AutomationTriggerTaskCreated, changes)
+        if err != nil {
+            tx.Rollback()
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run automations"})
+            return
+        }
+        automations = append(automations, taskAutomations)
         createdTasks = append(createdTasks, task)
     }
 
@@ -473,11 +546,19 @@ func GenerateTasks(c *gin.Context) {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to finalize task generation"})
         return
     }
+    for _, taskAutomations := range automations {
+        taskAutomations.send(h.hub)
+    }
 
-    c.JSON(http.StatusOK, gin.H{
+    response := gin.H{
         "message": fmt.Sprintf("Generated %d tasks successfully", len(createdTasks)),
         "tasks":   createdTasks,
-    })
+    }
+    if len(wipExceeded) > 0 {
+        response["wip_exceeded"] = wipExceeded
+    }
+
+    c.JSON(http.StatusOK, response)
 }
 
 func buildTaskGenerationPrompt(board models.Board, members []models.BoardMember, profiles map[uint]models.MemberProfile) string {
@@ -513,25 +594,30 @@ func buildTaskGenerationPrompt(board models.Board, members []models.BoardMember,
     prompt.WriteString("- Description (detailed explanation)\n")
     prompt.WriteString("- Priority (low, medium, or high)\n")
     prompt.WriteString("- Category (e.g., frontend, backend, design, testing, documentation)\n")
-    prompt.WriteString("- Suggested assignee (use the team member's name based on their skills and experience)\n")
+    prompt.WriteString("- Suggested assignees (use the team members' names based on their skills and experience; name several for pair work or reviews)\n")
+    prompt.WriteString("- Optional start date and due date for a realistic schedule\n")
     // Important: categories become board columns if missing; keep them short and consistent.
     prompt.WriteString("\nNotes:\n")
     prompt.WriteString("- Category will be used to create a column if it doesn't exist.\n")
     prompt.WriteString("- Use short, lowercase categories like: frontend, backend, design, testing, documentation, devops, research.\n")
     prompt.WriteString("- Priority must be one of: low, medium, high.\n")
-    prompt.WriteString("- For assignee_name, prefer an exact member name or email from the team list above.\n")
+    prompt.WriteString("- For assignee_names, prefer exact member names or emails from the team list above.\n")
+    prompt.WriteString(fmt.Sprintf("- Today is %s. Dates must use the YYYY-MM-DD format; leave them empty when unsure.\n", time.Now().Format("2006-01-02")))
     prompt.WriteString("\nReturn the tasks as a JSON array with the following structure:\n")
-    prompt.WriteString(`[{"title": "...", "description": "...", "priority": "...", "category": "...", "assignee_name": "..."}]`)
+    prompt.WriteString(`[{"title": "...", "description": "...", "priority": "...", "category": "...", "assignee_names": ["..."], "start_date": "...", "due_date": "..."}]`)
     
     return prompt.String()
 }
 
 type GeneratedTask struct {
-	Title        string `json:"title"`
-	Description  string `json:"description"`
-	Priority     string `json:"priority"`
-	Category     string `json:"category"`
-	AssigneeName string `json:"assignee_name"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Priority      string   `json:"priority"`
+	Category      string   `json:"category"`
+	AssigneeName  string   `json:"assignee_name"` // single assignee, still accepted from older prompts
+	AssigneeNames []string `json:"assignee_names"`
+	StartDate     string   `json:"start_date"` // optional, YYYY-MM-DD
+	DueDate       string   `json:"due_date"`   // optional, YYYY-MM-DD
 }
 
 func callLLMAPI(settings models.BoardSettings, prompt string) ([]GeneratedTask, error) {
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/mail"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/oidc"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcLoginTTL is how long a user has to finish signing in at the provider.
+	oidcLoginTTL = 10 * time.Minute
+
+	// ssoCodeTTL is how long the frontend has to trade the code it receives
+	// after an SSO login for tokens.
+	ssoCodeTTL = time.Minute
+
+	// oidcStateCookie ties a login to the browser that started it, so a
+	// callback URL from someone else's login is refused.
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+// OIDCHandler signs users in through the OpenID Connect provider. The
+// provider is nil when SSO is not configured.
+type OIDCHandler struct {
+	provider *oidc.Provider
+}
+
+func NewOIDCHandler(provider *oidc.Provider) *OIDCHandler {
+	return &OIDCHandler{provider: provider}
+}
+
+// GetSSOConfig tells the frontend whether to offer SSO and for which email
+// domains password login is disabled.
+func (h *OIDCHandler) GetSSOConfig(c *gin.Context) {
+	domains := oidc.EnforcedDomains()
+	if domains == nil {
+		domains = []string{}
+	}
+
+	if h.provider == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false, "enforced_domains": domains})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":          true,
+		"name":             h.provider.Name(),
+		"login_url":        "/api/auth/oidc/login",
+		"enforced_domains": domains,
+	})
+}
+
+// Login sends the browser to the provider. Pass redirect, a path of the
+// frontend, to land there once signed in.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if h.provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SSO is not configured"})
+		return
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start SSO login"})
+		return
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start SSO login"})
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start SSO login"})
+		return
+	}
+
+	authURL, err := h.provider.AuthCodeURL(c.Request.Context(), state, nonce, challenge)
+	if err != nil {
+		logger.Log.Errorf("Failed to reach the SSO provider: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "SSO provider is unavailable"})
+		return
+	}
+
+	now := time.Now().UTC()
+	db := database.GetDB()
+	if err := db.Where("expires_at < ?", now).Delete(&models.OIDCLoginAttempt{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start SSO login"})
+		return
+	}
+	attempt := models.OIDCLoginAttempt{
+		StateHash:    auth.HashOneTimeToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		RedirectPath: frontendPath(c.Query("redirect")),
+		ExpiresAt:    now.Add(oidcLoginTTL),
+	}
+	if err := db.Create(&attempt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start SSO login"})
+		return
+	}
+
+	setOIDCStateCookie(c, state, int(oidcLoginTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback is where the provider sends the browser back. It signs the user
+// in, linking or creating their account by verified email, and sends the
+// browser on to the frontend's /auth/sso page with a code to trade for
+// tokens, or with an error.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if h.provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "SSO is not configured"})
+		return
+	}
+
+	// The state must come back to the browser that was sent off with it
+	state := c.Query("state")
+	cookie, err := c.Cookie(oidcStateCookie)
+	setOIDCStateCookie(c, "", -1)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		redirectSSO(c, url.Values{"error": {"invalid_state"}})
+		return
+	}
+
+	db := database.GetDB()
+	var attempt models.OIDCLoginAttempt
+	if err := db.Where("state_hash = ?", auth.HashOneTimeToken(state)).First(&attempt).Error; err != nil {
+		redirectSSO(c, url.Values{"error": {"invalid_state"}})
+		return
+	}
+	// Each attempt is good for one callback
+	if result := db.Delete(&attempt); result.Error != nil || result.RowsAffected == 0 || time.Now().After(attempt.ExpiresAt) {
+		redirectSSO(c, url.Values{"error": {"invalid_state"}})
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		logger.Log.Infof("SSO provider refused the login: %s %s", providerError, c.Query("error_description"))
+		redirectSSO(c, url.Values{"error": {"access_denied"}})
+		return
+	}
+
+	identity, err := h.provider.Exchange(c.Request.Context(), c.Query("code"), attempt.CodeVerifier)
+	if err != nil {
+		logger.Log.Errorf("SSO login failed: %v", err)
+		redirectSSO(c, url.Values{"error": {"login_failed"}})
+		return
+	}
+	if identity.Nonce != attempt.Nonce {
+		logger.Log.Errorf("SSO login failed: nonce mismatch for subject %s", identity.Subject)
+		redirectSSO(c, url.Values{"error": {"login_failed"}})
+		return
+	}
+	if identity.Email == "" {
+		redirectSSO(c, url.Values{"error": {"email_missing"}})
+		return
+	}
+	if !identity.EmailVerified && h.provider.RequireVerifiedEmail() {
+		redirectSSO(c, url.Values{"error": {"email_not_verified"}})
+		return
+	}
+
+	user, err := linkOIDCIdentity(db, identity)
+	if errors.Is(err, errSSOAccountExists) {
+		redirectSSO(c, url.Values{"error": {"account_exists"}})
+		return
+	}
+	if err != nil {
+		logger.Log.Errorf("Failed to sign in SSO subject %s: %v", identity.Subject, err)
+		redirectSSO(c, url.Values{"error": {"login_failed"}})
+		return
+	}
+
+	code, err := issueOneTimeToken(db, user.ID, models.OneTimeTokenSSOLogin, ssoCodeTTL)
+	if err != nil {
+		redirectSSO(c, url.Values{"error": {"login_failed"}})
+		return
+	}
+
+	redirectSSO(c, url.Values{"code": {code}, "redirect": {attempt.RedirectPath}})
+}
+
+// Exchange trades the code from an SSO login for a session, answering like
+// login does.
+func (h *OIDCHandler) Exchange(c *gin.Context) {
+	var req models.SSOExchangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		token, err := consumeOneTimeToken(tx, req.Code, models.OneTimeTokenSSOLogin)
+		if err != nil {
+			return err
+		}
+		return tx.First(&user, token.UserID).Error
+	})
+	if errors.Is(err, errInvalidOneTimeToken) || errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired SSO code"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	completeLogin(c, http.StatusOK, &user)
+}
+
+var errSSOAccountExists = errors.New("an account with this email exists and the provider did not verify it")
+
+// linkOIDCIdentity returns the user an identity signs in as. Identities seen
+// before keep their user; new ones are linked to the account with their
+// email, which needs the provider to have verified it, or get a new account.
+func linkOIDCIdentity(db *gorm.DB, identity *oidc.Identity) (*models.User, error) {
+	var user models.User
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		var link models.UserIdentity
+		err := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+		if err == nil {
+			if err := tx.First(&user, link.UserID).Error; err != nil {
+				return err
+			}
+			return tx.Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": now}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		err = tx.Where("LOWER(email) = ?", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			if !identity.EmailVerified {
+				return errSSOAccountExists
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user, err = newSSOUser(identity)
+			if err != nil {
+				return err
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if identity.EmailVerified && user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+			if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:      user.ID,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// newSSOUser builds the account of a first-time SSO user. Its password is
+// random and unknown, so it can only be used through SSO or after a reset.
+func newSSOUser(identity *oidc.Identity) (models.User, error) {
+	secret, err := oidc.RandomString()
+	if err != nil {
+		return models.User{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name = identity.Email[:strings.LastIndex(identity.Email, "@")]
+	}
+	return models.User{
+		Email:    identity.Email,
+		Name:     name,
+		Password: string(hashedPassword),
+		Avatar:   defaultAvatarURL,
+	}, nil
+}
+
+// setOIDCStateCookie stores the state of a login in progress, or clears it
+// with a negative maxAge. Lax lets the cookie come along on the provider's
+// redirect back.
+func setOIDCStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, maxAge, oidcCookiePath, "", secure, true)
+}
+
+// redirectSSO sends the browser to the frontend's SSO landing page.
+func redirectSSO(c *gin.Context, query url.Values) {
+	c.Redirect(http.StatusFound, mail.AppURL()+"/auth/sso?"+query.Encode())
+}
+
+// frontendPath returns path if it is a path within the frontend, and "/"
+// otherwise, so the redirect cannot send users to another site.
+func frontendPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, "\\") {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPersonalAccessTokens lists the caller's personal access tokens, expired
+// ones included.
+func (h *AuthHandler) GetPersonalAccessTokens(c *gin.Context) {
+	tokens := []models.PersonalAccessToken{}
+	if err := database.GetDB().Where("user_id = ?", middleware.GetUserID(c)).Order("id desc").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreatePersonalAccessToken creates a token for the caller. The response
+// carries the token, which is not shown again.
+func (h *AuthHandler) CreatePersonalAccessToken(c *gin.Context) {
+	var req models.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !containsString(models.PersonalAccessTokenScopes, scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope " + scope, "scopes": models.PersonalAccessTokenScopes})
+			return
+		}
+	}
+
+	userID := middleware.GetUserID(c)
+	boardIDs := uniqueUints(req.BoardIDs)
+	for _, boardID := range boardIDs {
+		if !hasAccessToBoard(boardID, userID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You do not have access to board " + strconv.FormatUint(uint64(boardID), 10)})
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		expiry := req.ExpiresAt.UTC()
+		if !expiry.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = &expiry
+	}
+
+	secret, hash, err := auth.NewPersonalAccessToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	token := models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        req.Name,
+		TokenHash:   hash,
+		TokenPrefix: secret[:len(auth.PersonalAccessTokenPrefix)+6],
+		Scopes:      uniqueStrings(req.Scopes),
+		BoardIDs:    boardIDs,
+		ExpiresAt:   expiresAt,
+	}
+	if err := database.GetDB().Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.PersonalAccessTokenSecretResponse{PersonalAccessToken: token, Token: secret})
+}
+
+// DeletePersonalAccessToken revokes one of the caller's tokens.
+func (h *AuthHandler) DeletePersonalAccessToken(c *gin.Context) {
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	result := database.GetDB().Where("id = ? AND user_id = ?", tokenID, middleware.GetUserID(c)).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token deleted successfully"})
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/recurrence"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// upcomingOccurrences is how many future occurrences responses list.
+const upcomingOccurrences = 5
+
+type RecurringTaskHandler struct {
+	hub *websocket.Hub
+}
+
+func NewRecurringTaskHandler(hub *websocket.Hub) *RecurringTaskHandler {
+	return &RecurringTaskHandler{hub: hub}
+}
+
+// GetRecurringTasks returns a board's recurring tasks by next run.
+func (h *RecurringTaskHandler) GetRecurringTasks(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var recurringTasks []models.RecurringTask
+	if err := database.GetDB().Where("board_id = ?", boardID).
+		Order("next_run_at IS NULL").Order("next_run_at asc, id asc").
+		Find(&recurringTasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recurring tasks"})
+		return
+	}
+
+	responses := make([]models.RecurringTaskResponse, 0, len(recurringTasks))
+	for i := range recurringTasks {
+		responses = append(responses, recurringTaskResponse(&recurringTasks[i]))
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetRecurringTask returns a recurring task with its upcoming occurrences.
+func (h *RecurringTaskHandler) GetRecurringTask(c *gin.Context) {
+	recurringTask, ok := loadBoardRecurringTask(c, "")
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, recurringTaskResponse(recurringTask))
+}
+
+// CreateRecurringTask adds a task template that the scheduler clones into
+// the board on every occurrence of its rule. Requires create_task.
+func (h *RecurringTaskHandler) CreateRecurringTask(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "create_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.RecurringTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	rule, ok := checkRecurringTask(c, db, uint(boardID), &req)
+	if !ok {
+		return
+	}
+
+	recurringTask := models.RecurringTask{BoardID: uint(boardID), CreatedBy: userID}
+	applyRecurringTaskRequest(&recurringTask, &req, rule)
+	if err := db.Create(&recurringTask).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recurring task"})
+		return
+	}
+
+	response := recurringTaskResponse(&recurringTask)
+	h.hub.BroadcastToBoard(recurringTask.BoardID, "recurring_task_created", response)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateRecurringTask replaces the template and rule of a recurring task and
+// reschedules it from now. Requires edit_task.
+func (h *RecurringTaskHandler) UpdateRecurringTask(c *gin.Context) {
+	recurringTask, ok := loadBoardRecurringTask(c, "edit_task")
+	if !ok {
+		return
+	}
+
+	var req models.RecurringTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	rule, ok := checkRecurringTask(c, db, recurringTask.BoardID, &req)
+	if !ok {
+		return
+	}
+
+	applyRecurringTaskRequest(recurringTask, &req, rule)
+	if err := db.Save(recurringTask).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recurring task"})
+		return
+	}
+
+	response := recurringTaskResponse(recurringTask)
+	h.hub.BroadcastToBoard(recurringTask.BoardID, "recurring_task_updated", response)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteRecurringTask stops a recurring task. Tasks it already created are
+// kept. Requires delete_task.
+func (h *RecurringTaskHandler) DeleteRecurringTask(c *gin.Context) {
+	recurringTask, ok := loadBoardRecurringTask(c, "delete_task")
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recurring_task_id = ?", recurringTask.ID).Delete(&models.RecurringTaskRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(recurringTask).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recurring task"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(recurringTask.BoardID, "recurring_task_deleted", gin.H{"id": recurringTask.ID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recurring task deleted successfully"})
+}
+
+// loadBoardRecurringTask loads the :recurringId recurring task of the :id
+// board and checks that the caller holds action on it, or only board access
+// when action is empty. It writes the error response and returns ok false on
+// failure.
+func loadBoardRecurringTask(c *gin.Context, action string) (*models.RecurringTask, bool) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return nil, false
+	}
+	recurringID, err := strconv.ParseUint(c.Param("recurringId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recurring task ID"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if action == "" {
+		if !hasAccessToBoard(uint(boardID), userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return nil, false
+		}
+	} else if !hasPermissionOnBoard(uint(boardID), userID, action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+
+	var recurringTask models.RecurringTask
+	if err := database.GetDB().Where("id = ? AND board_id = ?", recurringID, boardID).First(&recurringTask).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring task not found"})
+		return nil, false
+	}
+	return &recurringTask, true
+}
+
+// checkRecurringTask validates the template and rule of a request and returns
+// the rule. It writes the error response and returns ok false on failure.
+func checkRecurringTask(c *gin.Context, db *gorm.DB, boardID uint, req *models.RecurringTaskRequest) (*recurrence.Rule, bool) {
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+
+	rule, err := recurrence.New(req.Frequency, req.Interval, req.ByDay, req.ByMonthDay, req.StartsAt, req.Until, req.Timezone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if !checkTaskStatus(c, db, boardID, req.Status) {
+		return nil, false
+	}
+	req.AssigneeIDs = uniqueUints(req.AssigneeIDs)
+	if !checkBoardMembers(c, db, boardID, req.AssigneeIDs, "assignee") {
+		return nil, false
+	}
+
+	req.LabelIDs = uniqueUints(req.LabelIDs)
+	if len(req.LabelIDs) > 0 {
+		var count int64
+		if err := db.Model(&models.Label{}).Where("id IN ? AND board_id = ?", req.LabelIDs, boardID).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check labels"})
+			return nil, false
+		}
+		if int(count) != len(req.LabelIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errUnknownLabel.Error()})
+			return nil, false
+		}
+	}
+
+	return rule, true
+}
+
+// applyRecurringTaskRequest copies a validated request onto recurringTask and
+// schedules its next run.
+func applyRecurringTaskRequest(recurringTask *models.RecurringTask, req *models.RecurringTaskRequest, rule *recurrence.Rule) {
+	recurringTask.Title = req.Title
+	recurringTask.Description = req.Description
+	recurringTask.Priority = req.Priority
+	recurringTask.Category = req.Category
+	recurringTask.Status = req.Status
+	recurringTask.AssigneeIDs = req.AssigneeIDs
+	recurringTask.LabelIDs = req.LabelIDs
+	recurringTask.EstimatedHours = req.EstimatedHours
+	recurringTask.DueInDays = req.DueInDays
+	recurringTask.Frequency = req.Frequency
+	recurringTask.Interval = req.Interval
+	recurringTask.ByDay = make([]string, 0, len(req.ByDay))
+	for _, day := range req.ByDay {
+		recurringTask.ByDay = append(recurringTask.ByDay, strings.ToUpper(day))
+	}
+	recurringTask.ByMonthDay = req.ByMonthDay
+	recurringTask.StartsAt = req.StartsAt
+	recurringTask.Until = req.Until
+	recurringTask.Timezone = req.Timezone
+	recurringTask.Paused = req.Paused
+
+	// Occurrences that already passed are not created retroactively
+	after := rule.Start.Add(-time.Nanosecond)
+	if now := time.Now(); now.After(after) {
+		after = now
+	}
+	recurringTask.NextRunAt = nil
+	if next, ok := rule.Next(after); ok {
+		next = next.UTC()
+		recurringTask.NextRunAt = &next
+	}
+}
+
+// recurringTaskResponse adds the upcoming occurrences to a recurring task.
+func recurringTaskResponse(recurringTask *models.RecurringTask) models.RecurringTaskResponse {
+	response := models.RecurringTaskResponse{RecurringTask: *recurringTask, Upcoming: []time.Time{}}
+	if recurringTask.NextRunAt == nil {
+		return response
+	}
+
+	rule, err := recurrence.New(recurringTask.Frequency, recurringTask.Interval, recurringTask.ByDay, recurringTask.ByMonthDay, recurringTask.StartsAt, recurringTask.Until, recurringTask.Timezone)
+	if err != nil {
+		return response
+	}
+	response.Upcoming = append(response.Upcoming, *recurringTask.NextRunAt)
+	for _, occurrence := range rule.Upcoming(*recurringTask.NextRunAt, upcomingOccurrences-1) {
+		response.Upcoming = append(response.Upcoming, occurrence.UTC())
+	}
+	return response
+}
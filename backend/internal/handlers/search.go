@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searcher search.Searcher
+}
+
+func NewSearchHandler(searcher search.Searcher) *SearchHandler {
+	return &SearchHandler{searcher: searcher}
+}
+
+// Search finds tasks, task comments, board chat and the caller's private
+// messages matching q, limited to boards the caller is a member of. With
+// board_id only that board's content is searched
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	text := strings.TrimSpace(c.Query("q"))
+	if len(search.Terms(text)) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query must contain at least one word"})
+		return
+	}
+
+	var types []string
+	if param := c.Query("type"); param != "" {
+		for _, t := range strings.Split(param, ",") {
+			if !search.IsType(t) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type: " + t})
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	page := 1
+	if p, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && p > 0 {
+		page = p
+	}
+
+	limit := 20
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "20")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	boardQuery := database.GetDB().Table("board_members").
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
+		Where("board_members.user_id = ?", userID).
+		Where(twoFactorMet, true)
+	if boardID := c.Query("board_id"); boardID != "" {
+		id, err := strconv.ParseUint(boardID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board_id"})
+			return
+		}
+		boardQuery = boardQuery.Where("board_members.board_id = ?", id)
+		if len(types) == 0 {
+			types = search.BoardTypes
+		}
+	}
+
+	var boardIDs []uint
+	if err := boardQuery.Pluck("board_members.board_id", &boardIDs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch boards"})
+		return
+	}
+
+	response, err := h.searcher.Search(search.Query{
+		Text:     text,
+		UserID:   userID,
+		BoardIDs: boardIDs,
+		Types:    types,
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	})
+	if err != nil {
+		logger.Log.Errorf("Search failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": response.Results,
+		"facets":  response.Facets,
+		"total":   response.Total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// startSession signs user in on a new session and writes the user with an
+// access and a refresh token.
+func startSession(c *gin.Context, status int, user *models.User) {
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	now := time.Now().UTC()
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: refreshHash,
+		UserAgent:        c.Request.UserAgent(),
+		IP:               c.ClientIP(),
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(auth.RefreshTokenTTL()),
+	}
+
+	db := database.GetDB()
+	// Sessions past their expiry are no use to anyone, not even for reuse detection
+	if err := db.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.Session{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+	if err := db.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	writeSessionTokens(c, status, user, &session, refreshToken)
+}
+
+// writeSessionTokens issues an access token for session and writes it with
+// the user and the session's current refresh token.
+func writeSessionTokens(c *gin.Context, status int, user *models.User, session *models.Session, refreshToken string) {
+	token, err := auth.GenerateToken(user.ID, user.Email, session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(status, gin.H{
+		"user":          newAccountResponse(user),
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(auth.AccessTokenTTL().Seconds()),
+	})
+}
+
+// Refresh trades a refresh token for a new access token and a new refresh
+// token. Each refresh token works once; presenting a rotated-out one again
+// means it leaked, and the whole session is revoked.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	presented := auth.HashRefreshToken(req.RefreshToken)
+	now := time.Now().UTC()
+	db := database.GetDB()
+
+	var session models.Session
+	if err := db.Where("refresh_token_hash = ?", presented).First(&session).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+		result := revokeSessions(db.Where("previous_token_hash = ?", presented))
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+			return
+		}
+		if result.RowsAffected > 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used; the session has been revoked"})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+	if !session.Active(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	// The hash condition makes concurrent refreshes of one token rotate it once
+	result := db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, presented).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  refreshHash,
+			"previous_token_hash": presented,
+			"last_used_at":        now,
+			"expires_at":          now.Add(auth.RefreshTokenTTL()),
+			"user_agent":          c.Request.UserAgent(),
+			"ip":                  c.ClientIP(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh session"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, session.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	writeSessionTokens(c, http.StatusOK, &user, &session, refreshToken)
+}
+
+// Logout revokes the session of the access token.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := revokeSessions(database.GetDB().Where("id = ?", middleware.GetSessionID(c))).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// GetSessions lists the caller's active sessions, most recently used first.
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	currentID := middleware.GetSessionID(c)
+
+	var sessions []models.Session
+	if err := database.GetDB().
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now().UTC()).
+		Order("last_used_at desc").
+		Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	response := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, models.SessionResponse{Session: session, Current: session.ID == currentID})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession signs one of the caller's sessions out.
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	var session models.Session
+	db := database.GetDB()
+	if err := db.Where("id = ? AND user_id = ?", sessionID, middleware.GetUserID(c)).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeSessions(db.Where("id = ?", session.ID)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions signs the caller out everywhere but the current session.
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	query := database.GetDB().Where("user_id = ? AND id <> ?", middleware.GetUserID(c), middleware.GetSessionID(c))
+	if err := revokeSessions(query).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked successfully"})
+}
+
+// revokeSessions revokes the sessions matched by query that are not revoked yet.
+func revokeSessions(query *gorm.DB) *gorm.DB {
+	return query.Model(&models.Session{}).Where("revoked_at IS NULL").Update("revoked_at", time.Now().UTC())
+}
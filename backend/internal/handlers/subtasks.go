@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errInvalidParent = errors.New("parent task must be on the same board")
+	errParentCycle   = errors.New("a task cannot be nested under itself or one of its subtasks")
+)
+
+// GetSubtasks returns the direct children of a task in column order.
+func (h *TaskHandler) GetSubtasks(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var children []models.Task
+	if err := db.Where("parent_id = ?", task.ID).Order("position asc, id asc").Find(&children).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subtasks"})
+		return
+	}
+
+	taskResponses := make([]models.TaskResponse, 0, len(children))
+	for _, child := range children {
+		var taskResponse models.TaskResponse
+		h.loadTaskResponse(child.ID, &taskResponse)
+		taskResponses = append(taskResponses, taskResponse)
+	}
+
+	c.JSON(http.StatusOK, taskResponses)
+}
+
+// SetParent nests a task under another task on the same board, or detaches
+// it when parent_id is null.
+func (h *TaskHandler) SetParent(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.SetTaskParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	oldParentID := task.ParentID
+	before := taskFields(&task, nil, nil)
+
+	tx := db.Begin()
+
+	if req.ParentID != nil {
+		if err := validateTaskParent(tx, &task, *req.ParentID); err != nil {
+			tx.Rollback()
+			if errors.Is(err, errInvalidParent) || errors.Is(err, errParentCycle) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate parent task"})
+			return
+		}
+	}
+
+	task.ParentID = req.ParentID
+	if err := tx.Model(&task).Update("parent_id", req.ParentID).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+
+	changes := diffTaskFields(before, taskFields(&task, nil, nil))
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionUpdated, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	tx.Commit()
+
+	var taskResponse models.TaskResponse
+	h.loadTaskResponse(task.ID, &taskResponse)
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_updated", taskResponse)
+	notifyWatchers(h.hub, &task, userID, "task_updated", gin.H{"changes": changes})
+	h.broadcastParentProgress(oldParentID)
+	h.broadcastParentProgress(task.ParentID)
+
+	c.JSON(http.StatusOK, taskResponse)
+}
+
+// GetChecklist returns a task's checklist items in order.
+func (h *TaskHandler) GetChecklist(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	items, err := checklistItems(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checklist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *TaskHandler) CreateChecklistItem(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var maxPos int
+	db.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Select("COALESCE(MAX(position),0)").Scan(&maxPos)
+
+	item := models.ChecklistItem{
+		TaskID:   task.ID,
+		Title:    req.Title,
+		Position: maxPos + 1,
+	}
+	if err := db.Create(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create checklist item"})
+		return
+	}
+
+	h.broadcastChecklist(&task, middleware.GetUserID(c))
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChecklistItem renames an item or checks it off.
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	task, item, ok := h.loadChecklistItem(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Title != nil {
+		item.Title = *req.Title
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+
+	if err := database.GetDB().Save(item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+		return
+	}
+
+	h.broadcastChecklist(task, middleware.GetUserID(c))
+
+	c.JSON(http.StatusOK, item)
+}
+
+// ToggleChecklistItem flips an item between open and done.
+func (h *TaskHandler) ToggleChecklistItem(c *gin.Context) {
+	task, item, ok := h.loadChecklistItem(c)
+	if !ok {
+		return
+	}
+
+	item.Done = !item.Done
+	if err := database.GetDB().Model(item).Update("done", item.Done).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+		return
+	}
+
+	h.broadcastChecklist(task, middleware.GetUserID(c))
+
+	c.JSON(http.StatusOK, item)
+}
+
+// ReorderChecklist rewrites item positions from the submitted order. Every
+// item of the task must be listed exactly once.
+func (h *TaskHandler) ReorderChecklist(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := checklistItems(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch checklist"})
+		return
+	}
+
+	byID := make(map[uint]bool, len(items))
+	for _, item := range items {
+		byID[item.ID] = true
+	}
+	seen := make(map[uint]bool, len(req.ItemIDs))
+	for _, id := range req.ItemIDs {
+		if !byID[id] || seen[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item_ids must list every checklist item of the task exactly once"})
+			return
+		}
+		seen[id] = true
+	}
+	if len(seen) != len(items) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "item_ids must list every checklist item of the task exactly once"})
+		return
+	}
+
+	tx := db.Begin()
+	for i, id := range req.ItemIDs {
+		if err := tx.Model(&models.ChecklistItem{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder checklist"})
+			return
+		}
+	}
+	tx.Commit()
+
+	items, _ = checklistItems(db, task.ID)
+	h.broadcastChecklist(&task, middleware.GetUserID(c))
+
+	c.JSON(http.StatusOK, items)
+}
+
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	task, item, ok := h.loadChecklistItem(c)
+	if !ok {
+		return
+	}
+
+	if err := database.GetDB().Delete(item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete checklist item"})
+		return
+	}
+
+	h.broadcastChecklist(task, middleware.GetUserID(c))
+
+	c.JSON(http.StatusOK, gin.H{"message": "Checklist item deleted successfully"})
+}
+
+// loadChecklistItem resolves the :id and :itemId params for a checklist
+// change, writing an error response and returning ok false on failure.
+func (h *TaskHandler) loadChecklistItem(c *gin.Context) (*models.Task, *models.ChecklistItem, bool) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return nil, nil, false
+	}
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist item ID"})
+		return nil, nil, false
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, nil, false
+	}
+
+	var item models.ChecklistItem
+	if err := db.Where("id = ? AND task_id = ?", itemID, task.ID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return nil, nil, false
+	}
+
+	return &task, &item, true
+}
+
+// broadcastChecklist sends a task's current checklist and progress to the
+// board and tells the task's watchers about the change.
+func (h *TaskHandler) broadcastChecklist(task *models.Task, actorID uint) {
+	db := database.GetDB()
+	items, err := checklistItems(db, task.ID)
+	if err != nil {
+		return
+	}
+	progress, _ := taskProgress(db, task)
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_checklist_updated", gin.H{
+		"task_id":  task.ID,
+		"items":    items,
+		"progress": progress,
+	})
+	notifyWatchers(h.hub, task, actorID, "task_checklist_updated", gin.H{"progress": progress})
+}
+
+// broadcastParentProgress re-sends a parent task after one of its subtasks
+// changed so boards can refresh its progress.
+func (h *TaskHandler) broadcastParentProgress(parentID *uint) {
+	if parentID == nil {
+		return
+	}
+	var taskResponse models.TaskResponse
+	if err := h.loadTaskResponse(*parentID, &taskResponse); err != nil {
+		return
+	}
+	h.hub.BroadcastToBoard(taskResponse.BoardID, "task_updated", taskResponse)
+}
+
+func checklistItems(db *gorm.DB, taskID uint) ([]models.ChecklistItem, error) {
+	items := []models.ChecklistItem{}
+	err := db.Where("task_id = ?", taskID).Order("position asc, id asc").Find(&items).Error
+	return items, err
+}
+
+// doneStatus returns the status of the board's last column, which is where
+// finished work ends up.
+func doneStatus(db *gorm.DB, boardID uint) (string, error) {
+	statuses, err := boardStatuses(db, boardID)
+	if err != nil {
+		return "", err
+	}
+	return statuses[len(statuses)-1], nil
+}
+
+// taskProgress counts the checked checklist items and the finished subtasks
+// of a task.
+func taskProgress(db *gorm.DB, task *models.Task) (models.TaskProgress, error) {
+	var progress models.TaskProgress
+
+	if err := db.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Count(&progress.ChecklistTotal).Error; err != nil {
+		return progress, err
+	}
+	if progress.ChecklistTotal > 0 {
+		if err := db.Model(&models.ChecklistItem{}).Where("task_id = ? AND done = ?", task.ID, true).Count(&progress.ChecklistDone).Error; err != nil {
+			return progress, err
+		}
+	}
+
+	if err := db.Model(&models.Task{}).Where("parent_id = ?", task.ID).Count(&progress.SubtasksTotal).Error; err != nil {
+		return progress, err
+	}
+	if progress.SubtasksTotal > 0 {
+		done, err := doneStatus(db, task.BoardID)
+		if err != nil {
+			return progress, err
+		}
+		if err := db.Model(&models.Task{}).Where("parent_id = ? AND status = ?", task.ID, done).Count(&progress.SubtasksDone).Error; err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, nil
+}
+
+// validateTaskParent checks that parentID is a live task on the same board
+// and that nesting task under it would not create a cycle. Run it in the
+// transaction that sets the parent: on Postgres the task and the ancestors
+// walked stay locked, so crossing reparents cannot both pass.
+func validateTaskParent(db *gorm.DB, task *models.Task, parentID uint) error {
+	lock := db.Dialector.Name() == "postgres"
+	if lock && task.ID != 0 {
+		if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Task{}, task.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	visited := map[uint]bool{}
+	current := &parentID
+	for current != nil {
+		if task.ID != 0 && *current == task.ID {
+			return errParentCycle
+		}
+		// Guards the walk against a cycle that is already stored
+		if visited[*current] {
+			return errParentCycle
+		}
+		visited[*current] = true
+
+		query := db.Select("id, board_id, parent_id")
+		if lock {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		var ancestor models.Task
+		if err := query.First(&ancestor, *current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if *current == parentID {
+					return errInvalidParent
+				}
+				return nil
+			}
+			return err
+		}
+		if ancestor.BoardID != task.BoardID {
+			return errInvalidParent
+		}
+		current = ancestor.ParentID
+	}
+	return nil
+}
+
+// checkOpenSubtasks stops a parent from reaching the done column while it
+// still has open subtasks, if the board asks for that. It writes a 409 and
+// returns false when the change is blocked.
+func checkOpenSubtasks(c *gin.Context, db *gorm.DB, task *models.Task, status string) bool {
+	open, err := openSubtasksBlockingDone(db, task, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subtasks"})
+		return false
+	}
+	if open > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":         "Task has open subtasks",
+			"code":          "open_subtasks",
+			"open_subtasks": open,
+		})
+		return false
+	}
+	return true
+}
+
+// openSubtasksBlockingDone returns how many open subtasks keep task from
+// moving to status. That is none unless status is the done column of a board
+// that blocks done with open subtasks.
+func openSubtasksBlockingDone(db *gorm.DB, task *models.Task, status string) (int64, error) {
+	var settings models.BoardSettings
+	if err := db.Where("board_id = ?", task.BoardID).First(&settings).Error; err != nil || !settings.BlockDoneWithOpenSubtasks {
+		return 0, nil
+	}
+
+	done, err := doneStatus(db, task.BoardID)
+	if err != nil || status != done {
+		return 0, err
+	}
+
+	var open int64
+	err = db.Model(&models.Task{}).Where("parent_id = ? AND status <> ?", task.ID, done).Count(&open).Error
+	return open, err
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetTaskActivity returns a task's audit trail, newest first. It keeps
+// working after the task has been deleted.
+func (h *TaskHandler) GetTaskActivity(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var boardID uint
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err == nil {
+		boardID = task.BoardID
+	} else {
+		var activity models.TaskActivity
+		if err := db.Where("task_id = ?", taskID).First(&activity).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		boardID = activity.BoardID
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(boardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	respondActivityPage(c, db.Where("task_id = ?", taskID))
+}
+
+// GetBoardActivity returns the audit trail of every task on a board, newest first.
+func (h *TaskHandler) GetBoardActivity(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	respondActivityPage(c, database.GetDB().Where("board_id = ?", boardID))
+}
+
+// respondActivityPage writes one page of activity entries matched by query.
+// The cursor is the ID of the last entry of the previous page.
+func respondActivityPage(c *gin.Context, query *gorm.DB) {
+	limit := 50
+	if l, err := strconv.Atoi(c.DefaultQuery("limit", "50")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		cursorID, err := strconv.ParseUint(cursor, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		query = query.Where("id < ?", cursorID)
+	}
+
+	var activities []models.TaskActivity
+	if err := query.Preload("Actor").Order("id desc").Limit(limit + 1).Find(&activities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity"})
+		return
+	}
+
+	var nextCursor *uint
+	if len(activities) > limit {
+		activities = activities[:limit]
+		nextCursor = &activities[limit-1].ID
+	}
+
+	responses := make([]models.TaskActivityResponse, 0, len(activities))
+	for _, activity := range activities {
+		response := models.TaskActivityResponse{
+			ID:        activity.ID,
+			TaskID:    activity.TaskID,
+			BoardID:   activity.BoardID,
+			ActorID:   activity.ActorID,
+			Action:    activity.Action,
+			Source:    activity.Source,
+			Changes:   activity.Changes,
+			CreatedAt: activity.CreatedAt,
+		}
+		if activity.Actor != nil {
+			response.ActorName = activity.Actor.Name
+			response.ActorAvatar = activity.Actor.Avatar
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"activities":  responses,
+		"next_cursor": nextCursor,
+	})
+}
+
+// taskField is a named task value tracked by the audit trail.
+type taskField struct {
+	name  string
+	value interface{}
+}
+
+// taskFields lists the audited fields of a task in a stable order. Pointer
+// fields are dereferenced so unchanged values compare equal. Label names and
+// assignee IDs are loaded by the caller and passed sorted.
+func taskFields(task *models.Task, labels []string, assignees []uint) []taskField {
+	if labels == nil {
+		labels = []string{}
+	}
+	if assignees == nil {
+		assignees = []uint{}
+	}
+	return []taskField{
+		{"title", task.Title},
+		{"description", task.Description},
+		{"priority", task.Priority},
+		{"category", task.Category},
+		{"status", task.Status},
+		{"position", task.Position},
+		{"parent_id", derefUint(task.ParentID)},
+		{"assignee_ids", assignees},
+		{"estimated_hours", derefFloat(task.EstimatedHours)},
+		{"actual_hours", derefFloat(task.ActualHours)},
+		{"start_date", derefTime(task.StartDate)},
+		{"due_date", derefTime(task.DueDate)},
+		{"labels", labels},
+	}
+}
+
+// diffTaskFields returns a change for every field whose value differs.
+func diffTaskFields(before, after []taskField) []models.FieldChange {
+	changes := []models.FieldChange{}
+	for i := range before {
+		if !reflect.DeepEqual(before[i].value, after[i].value) {
+			changes = append(changes, models.FieldChange{Field: before[i].name, Old: before[i].value, New: after[i].value})
+		}
+	}
+	return changes
+}
+
+// snapshotTaskFields records every set field of a task, as the new value when
+// created or the old value when deleted.
+func snapshotTaskFields(fields []taskField, asNew bool) []models.FieldChange {
+	empty := taskFields(&models.Task{}, nil, nil)
+	changes := []models.FieldChange{}
+	for i, field := range fields {
+		if reflect.DeepEqual(field.value, empty[i].value) {
+			continue
+		}
+		change := models.FieldChange{Field: field.name}
+		if asNew {
+			change.New = field.value
+		} else {
+			change.Old = field.value
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// recordTaskActivity writes an audit entry for task inside tx. Updates that
+// changed nothing are not recorded.
+func recordTaskActivity(tx *gorm.DB, task *models.Task, actorID uint, action, source string, changes []models.FieldChange) error {
+	if action == models.TaskActionUpdated && len(changes) == 0 {
+		return nil
+	}
+
+	activity := models.TaskActivity{
+		TaskID:  task.ID,
+		BoardID: task.BoardID,
+		Action:  action,
+		Source:  source,
+		Changes: changes,
+	}
+	if actorID != 0 {
+		activity.ActorID = &actorID
+	}
+	return tx.Create(&activity).Error
+}
+
+func derefUint(v *uint) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func derefFloat(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// derefTime formats timestamps so that equal instants compare equal.
+func derefTime(v *time.Time) interface{} {
+	if v == nil {
+		return nil
+	}
+	return v.UTC().Format(time.RFC3339)
+}
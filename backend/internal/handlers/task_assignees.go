@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetWatchers returns the users watching a task.
+func (h *TaskHandler) GetWatchers(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	users := []models.User{}
+	if err := db.Joins("JOIN task_watchers ON task_watchers.user_id = users.id").
+		Where("task_watchers.task_id = ?", taskID).
+		Order("users.name asc").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// WatchTask subscribes the caller to a task. Any board member, viewers
+// included, may watch.
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	h.setWatching(c, true)
+}
+
+// UnwatchTask unsubscribes the caller from a task.
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	h.setWatching(c, false)
+}
+
+func (h *TaskHandler) setWatching(c *gin.Context, watching bool) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	watcher := models.TaskWatcher{TaskID: task.ID, UserID: userID}
+	if watching {
+		err = db.Where(&watcher).FirstOrCreate(&watcher).Error
+	} else {
+		err = db.Where(&watcher).Delete(&models.TaskWatcher{}).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update watchers"})
+		return
+	}
+
+	watcherIDs, err := taskUserIDs(db, &models.TaskWatcher{}, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch watchers"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_watchers_updated", gin.H{
+		"task_id":     task.ID,
+		"watcher_ids": watcherIDs,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"task_id": task.ID, "watching": watching, "watcher_ids": watcherIDs})
+}
+
+// requestAssignees returns the assignees a task request asks for: the
+// assignee_ids list, or the single legacy assignee_id when the list is omitted.
+func requestAssignees(ids []uint, legacy *uint) []uint {
+	if ids != nil {
+		return uniqueUints(ids)
+	}
+	if legacy != nil {
+		return []uint{*legacy}
+	}
+	return []uint{}
+}
+
+// checkBoardMembers writes a 400 response and returns false unless every user
+// in ids is a member of the board. role names the users in the error.
+func checkBoardMembers(c *gin.Context, db *gorm.DB, boardID uint, ids []uint, role string) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	var count int64
+	if err := db.Model(&models.BoardMember{}).Where("board_id = ? AND user_id IN ?", boardID, ids).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check board members"})
+		return false
+	}
+	if int(count) != len(ids) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Every " + role + " must be a board member"})
+		return false
+	}
+	return true
+}
+
+// setTaskAssignees replaces the assignees of a task and mirrors the first one
+// into its assignee_id column.
+func setTaskAssignees(tx *gorm.DB, task *models.Task, ids []uint) error {
+	if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskAssignee{}).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := tx.Create(&models.TaskAssignee{TaskID: task.ID, UserID: id}).Error; err != nil {
+			return err
+		}
+	}
+
+	task.AssigneeID = nil
+	if len(ids) > 0 {
+		task.AssigneeID = &ids[0]
+	}
+	return tx.Model(task).Update("assignee_id", task.AssigneeID).Error
+}
+
+// setTaskWatchers replaces the watchers of a task.
+func setTaskWatchers(tx *gorm.DB, taskID uint, ids []uint) error {
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskWatcher{}).Error; err != nil {
+		return err
+	}
+	for _, id := range uniqueUints(ids) {
+		if err := tx.Create(&models.TaskWatcher{TaskID: taskID, UserID: id}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskUserIDs loads the sorted user IDs of a task from the assignee or
+// watcher table model refers to.
+func taskUserIDs(db *gorm.DB, model interface{}, taskID uint) ([]uint, error) {
+	ids := []uint{}
+	if err := db.Model(model).Where("task_id = ?", taskID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// sortedUints returns a sorted copy of ids.
+func sortedUints(ids []uint) []uint {
+	sorted := append([]uint{}, ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// notifyWatchers sends a private task_watch event about a change to every
+// watcher of task who is still a board member, except the actor.
+func notifyWatchers(hub *websocket.Hub, task *models.Task, actorID uint, event string, data interface{}) {
+	var watcherIDs []uint
+	if err := database.GetDB().Model(&models.TaskWatcher{}).
+		Joins("JOIN board_members ON board_members.user_id = task_watchers.user_id AND board_members.board_id = ?", task.BoardID).
+		Where("task_watchers.task_id = ? AND task_watchers.user_id <> ?", task.ID, actorID).
+		Pluck("task_watchers.user_id", &watcherIDs).Error; err != nil {
+		return
+	}
+
+	for _, id := range watcherIDs {
+		hub.BroadcastPrivateMessage(id, "task_watch", gin.H{
+			"event":    event,
+			"task_id":  task.ID,
+			"board_id": task.BoardID,
+			"title":    task.Title,
+			"actor_id": actorID,
+			"data":     data,
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// mentionPattern matches @handle and @user@example.com mentions.
+var mentionPattern = regexp.MustCompile(`@([\w.+-]+(?:@[\w-]+(?:\.[\w-]+)+)?)`)
+
+type TaskCommentHandler struct {
+	hub *websocket.Hub
+}
+
+func NewTaskCommentHandler(hub *websocket.Hub) *TaskCommentHandler {
+	return &TaskCommentHandler{hub: hub}
+}
+
+// GetComments returns a task's comments, oldest first.
+func (h *TaskCommentHandler) GetComments(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var comments []models.TaskComment
+	if err := db.Preload("User").Where("task_id = ?", taskID).Order("created_at asc, id asc").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, taskCommentResponses(db, comments))
+}
+
+// CreateComment adds a comment to a task and notifies every board member it
+// mentions. Any board member, viewers included, may comment.
+func (h *TaskCommentHandler) CreateComment(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req models.TaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment content cannot be empty"})
+		return
+	}
+
+	mentions, err := resolveMentions(db, task.BoardID, req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+		return
+	}
+
+	comment := models.TaskComment{
+		TaskID:   task.ID,
+		BoardID:  task.BoardID,
+		UserID:   userID,
+		Content:  req.Content,
+		Mentions: mentions,
+	}
+	if err := db.Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+
+	db.First(&comment.User, userID)
+	response := taskCommentResponse(db, &comment)
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_comment_created", response)
+	h.notifyMentions(&task, response, mentions)
+	notifyWatchers(h.hub, &task, userID, "task_comment_created", response)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateComment edits one of the caller's comments. The previous content is
+// kept as a revision and only newly mentioned members are notified.
+func (h *TaskCommentHandler) UpdateComment(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var comment models.TaskComment
+	if err := db.Preload("User").Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(task.BoardID, userID) || comment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit a comment"})
+		return
+	}
+
+	var req models.TaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment content cannot be empty"})
+		return
+	}
+
+	if req.Content == comment.Content {
+		c.JSON(http.StatusOK, taskCommentResponse(db, &comment))
+		return
+	}
+
+	mentions, err := resolveMentions(db, task.BoardID, req.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve mentions"})
+		return
+	}
+
+	var newMentions []uint
+	for _, id := range mentions {
+		if !containsUint(comment.Mentions, id) {
+			newMentions = append(newMentions, id)
+		}
+	}
+
+	tx := db.Begin()
+
+	revision := models.TaskCommentRevision{
+		CommentID: comment.ID,
+		Content:   comment.Content,
+		EditedBy:  userID,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+
+	now := time.Now()
+	comment.Content = req.Content
+	comment.Mentions = mentions
+	comment.EditedAt = &now
+	if err := tx.Save(&comment).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+
+	tx.Commit()
+
+	response := taskCommentResponse(db, &comment)
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_comment_updated", response)
+	h.notifyMentions(&task, response, newMentions)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteComment removes a comment and its edit history. Authors can delete
+// their own comments and board managers can delete any.
+func (h *TaskCommentHandler) DeleteComment(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var comment models.TaskComment
+	if err := db.Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	canDelete := false
+	if comment.UserID == userID && hasAccessToBoard(comment.BoardID, userID) {
+		canDelete = true
+	} else if hasPermissionOnBoard(comment.BoardID, userID, "manage_board") {
+		canDelete = true
+	}
+
+	if !canDelete {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	tx := db.Begin()
+
+	if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.TaskCommentRevision{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	if err := tx.Delete(&comment).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+
+	tx.Commit()
+
+	h.hub.BroadcastToBoard(comment.BoardID, "task_comment_deleted", gin.H{
+		"comment_id": comment.ID,
+		"task_id":    comment.TaskID,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// GetCommentHistory returns the earlier versions of a comment, newest first.
+func (h *TaskCommentHandler) GetCommentHistory(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	commentID, err := strconv.ParseUint(c.Param("commentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var comment models.TaskComment
+	if err := db.Where("id = ? AND task_id = ?", commentID, taskID).First(&comment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasAccessToBoard(comment.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var revisions []models.TaskCommentRevision
+	if err := db.Where("comment_id = ?", comment.ID).Order("id desc").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comment history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// notifyMentions sends a task_mention notification over the private channel
+// to each mentioned user except the comment's author.
+func (h *TaskCommentHandler) notifyMentions(task *models.Task, comment models.TaskCommentResponse, userIDs []uint) {
+	for _, id := range userIDs {
+		if id == comment.UserID {
+			continue
+		}
+		h.hub.BroadcastPrivateMessage(id, "task_mention", gin.H{
+			"board_id":     task.BoardID,
+			"task_id":      task.ID,
+			"task_title":   task.Title,
+			"mentioned_by": comment.UserName,
+			"comment":      comment,
+		})
+	}
+}
+
+// resolveMentions returns the IDs of the board members @mentioned in content,
+// in order of first mention. A mention matches a member's email, the part of
+// the email before the @, or the name without spaces, ignoring case.
+func resolveMentions(db *gorm.DB, boardID uint, content string) ([]uint, error) {
+	matches := mentionPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return []uint{}, nil
+	}
+
+	var members []models.BoardMember
+	if err := db.Preload("User").Where("board_id = ?", boardID).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	handles := make(map[string]uint)
+	for _, member := range members {
+		email := strings.ToLower(member.User.Email)
+		handles[email] = member.UserID
+		if at := strings.Index(email, "@"); at > 0 {
+			handles[email[:at]] = member.UserID
+		}
+		if name := strings.ToLower(strings.ReplaceAll(member.User.Name, " ", "")); name != "" {
+			handles[name] = member.UserID
+		}
+	}
+
+	mentions := []uint{}
+	for _, match := range matches {
+		handle := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if id, ok := handles[handle]; ok && !containsUint(mentions, id) {
+			mentions = append(mentions, id)
+		}
+	}
+	return mentions, nil
+}
+
+// taskCommentResponse builds the API view of a comment. The author must be
+// preloaded.
+func taskCommentResponse(db *gorm.DB, comment *models.TaskComment) models.TaskCommentResponse {
+	return taskCommentResponses(db, []models.TaskComment{*comment})[0]
+}
+
+// taskCommentResponses builds the responses of comments, loading the names of
+// everyone they mention in one query.
+func taskCommentResponses(db *gorm.DB, comments []models.TaskComment) []models.TaskCommentResponse {
+	seen := make(map[uint]bool)
+	var mentioned []uint
+	for _, comment := range comments {
+		for _, id := range comment.Mentions {
+			if !seen[id] {
+				seen[id] = true
+				mentioned = append(mentioned, id)
+			}
+		}
+	}
+
+	names := make(map[uint]string, len(mentioned))
+	if len(mentioned) > 0 {
+		var users []models.User
+		db.Select("id, name").Where("id IN ?", mentioned).Find(&users)
+		for _, user := range users {
+			names[user.ID] = user.Name
+		}
+	}
+
+	responses := make([]models.TaskCommentResponse, 0, len(comments))
+	for i := range comments {
+		comment := &comments[i]
+		response := models.TaskCommentResponse{
+			ID:        comment.ID,
+			TaskID:    comment.TaskID,
+			BoardID:   comment.BoardID,
+			UserID:    comment.UserID,
+			UserName:  comment.User.Name,
+			Avatar:    comment.User.Avatar,
+			Content:   comment.Content,
+			Mentions:  []models.CommentMention{},
+			EditedAt:  comment.EditedAt,
+			CreatedAt: comment.CreatedAt,
+			UpdatedAt: comment.UpdatedAt,
+		}
+		for _, id := range comment.Mentions {
+			response.Mentions = append(response.Mentions, models.CommentMention{UserID: id, Name: names[id]})
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
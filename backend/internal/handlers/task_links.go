@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errLinkExists      = errors.New("tasks are already linked")
+	errDependencyCycle = errors.New("link would create a dependency cycle")
+)
+
+// GetTaskLinks returns every link that starts or ends at a task.
+func (h *TaskHandler) GetTaskLinks(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(task.BoardID, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	links := []models.TaskLink{}
+	if err := db.Where("source_id = ? OR target_id = ?", task.ID, task.ID).Order("id asc").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// CreateTaskLink links a task to another task on the same board. Blocks links
+// that would close a cycle are rejected.
+func (h *TaskHandler) CreateTaskLink(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateTaskLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.TargetID == task.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A task cannot be linked to itself"})
+		return
+	}
+
+	var target models.Task
+	if err := db.Where("id = ? AND board_id = ?", req.TargetID, task.BoardID).First(&target).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Linked task must be on the same board"})
+		return
+	}
+
+	link := models.TaskLink{
+		BoardID:   task.BoardID,
+		SourceID:  task.ID,
+		TargetID:  target.ID,
+		Type:      req.Type,
+		CreatedBy: userID,
+	}
+	if req.Type == "blocked_by" {
+		link.SourceID, link.TargetID = target.ID, task.ID
+		link.Type = models.TaskLinkBlocks
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := lockBoardTasks(tx, task.BoardID); err != nil {
+			return err
+		}
+
+		var existing int64
+		if err := tx.Model(&models.TaskLink{}).Where("source_id = ? AND target_id = ? AND type = ?", link.SourceID, link.TargetID, link.Type).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errLinkExists
+		}
+
+		if link.Type == models.TaskLinkBlocks {
+			cycle, err := blocksPathExists(tx, task.BoardID, link.TargetID, link.SourceID)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return errDependencyCycle
+			}
+		}
+
+		return tx.Create(&link).Error
+	})
+	switch {
+	case errors.Is(err, errLinkExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Tasks are already linked"})
+		return
+	case errors.Is(err, errDependencyCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": "Link would create a dependency cycle", "code": "dependency_cycle"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task link"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_link_created", link)
+	notifyWatchers(h.hub, &task, userID, "task_link_created", link)
+
+	c.JSON(http.StatusCreated, link)
+}
+
+func (h *TaskHandler) DeleteTaskLink(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	linkID, err := strconv.ParseUint(c.Param("linkId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid link ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var link models.TaskLink
+	if err := db.Where("id = ? AND (source_id = ? OR target_id = ?)", linkID, taskID, taskID).First(&link).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task link not found"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(link.BoardID, userID, "edit_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	if err := db.Delete(&link).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task link"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(link.BoardID, "task_link_deleted", gin.H{"link_id": link.ID})
+	var task models.Task
+	if err := db.First(&task, taskID).Error; err == nil {
+		notifyWatchers(h.hub, &task, userID, "task_link_deleted", gin.H{"link_id": link.ID})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task link deleted successfully"})
+}
+
+// GetDependencyGraph returns the board's live tasks as nodes and their links
+// as edges for the planning view.
+func (h *TaskHandler) GetDependencyGraph(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var tasks []models.Task
+	if err := db.Select("id, title, status").Where("board_id = ?", boardID).Order("id asc").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	var links []models.TaskLink
+	if err := db.Where("board_id = ?", boardID).Order("id asc").Find(&links).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch task links"})
+		return
+	}
+
+	done, err := doneStatus(db, uint(boardID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+		return
+	}
+
+	statuses := make(map[uint]string, len(tasks))
+	for _, task := range tasks {
+		statuses[task.ID] = task.Status
+	}
+
+	graph := models.DependencyGraph{
+		Nodes: []models.DependencyGraphNode{},
+		Edges: []models.DependencyGraphEdge{},
+	}
+	blocked := make(map[uint]bool)
+	for _, link := range links {
+		sourceStatus, sourceLive := statuses[link.SourceID]
+		if _, targetLive := statuses[link.TargetID]; !sourceLive || !targetLive {
+			continue
+		}
+		if link.Type == models.TaskLinkBlocks && sourceStatus != done {
+			blocked[link.TargetID] = true
+		}
+		graph.Edges = append(graph.Edges, models.DependencyGraphEdge{
+			ID:     link.ID,
+			Source: link.SourceID,
+			Target: link.TargetID,
+			Type:   link.Type,
+		})
+	}
+	for _, task := range tasks {
+		graph.Nodes = append(graph.Nodes, models.DependencyGraphNode{
+			ID:      task.ID,
+			Title:   task.Title,
+			Status:  task.Status,
+			Blocked: blocked[task.ID],
+		})
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// lockBoardTasks locks the board's task rows on Postgres so concurrent link
+// changes on the board are checked one after the other. SQLite already
+// serializes writers.
+func lockBoardTasks(tx *gorm.DB, boardID uint) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	var ids []uint
+	return tx.Model(&models.Task{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("board_id = ?", boardID).Pluck("id", &ids).Error
+}
+
+// blocksPathExists reports whether from reaches to by following blocks links
+// on the board.
+func blocksPathExists(db *gorm.DB, boardID, from, to uint) (bool, error) {
+	var links []models.TaskLink
+	if err := db.Select("source_id, target_id").Where("board_id = ? AND type = ?", boardID, models.TaskLinkBlocks).Find(&links).Error; err != nil {
+		return false, err
+	}
+
+	next := make(map[uint][]uint)
+	for _, link := range links {
+		next[link.SourceID] = append(next[link.SourceID], link.TargetID)
+	}
+
+	visited := map[uint]bool{from: true}
+	queue := []uint{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			return true, nil
+		}
+		for _, id := range next[current] {
+			if !visited[id] {
+				visited[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+// openBlockers returns the IDs of live tasks that block task and are not yet
+// in the board's done column.
+func openBlockers(db *gorm.DB, task *models.Task) ([]uint, error) {
+	done, err := doneStatus(db, task.BoardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	err = db.Model(&models.TaskLink{}).
+		Joins("JOIN tasks ON tasks.id = task_links.source_id AND tasks.deleted_at IS NULL").
+		Where("task_links.target_id = ? AND task_links.type = ? AND tasks.status <> ?", task.ID, models.TaskLinkBlocks, done).
+		Order("task_links.source_id asc").
+		Pluck("task_links.source_id", &ids).Error
+	return ids, err
+}
+
+// checkOpenBlockers stops a blocked task from leaving the board's first
+// column while its blockers are open, if the board asks for that. It writes
+// a 409 and returns false when the change is blocked.
+func checkOpenBlockers(c *gin.Context, db *gorm.DB, task *models.Task, status string) bool {
+	blockers, err := blockersPreventingStart(db, task, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check dependencies"})
+		return false
+	}
+	if len(blockers) > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       "Task is blocked by open tasks",
+			"code":        "blocked_task",
+			"blocker_ids": blockers,
+		})
+		return false
+	}
+	return true
+}
+
+// blockersPreventingStart returns the open blockers that keep task from
+// moving to status. There are none unless status is past the first column of
+// a board that blocks starting blocked tasks.
+func blockersPreventingStart(db *gorm.DB, task *models.Task, status string) ([]uint, error) {
+	var settings models.BoardSettings
+	if err := db.Where("board_id = ?", task.BoardID).First(&settings).Error; err != nil || !settings.BlockStartWithOpenBlockers {
+		return nil, nil
+	}
+
+	statuses, err := boardStatuses(db, task.BoardID)
+	if err != nil || status == statuses[0] {
+		return nil, err
+	}
+	return openBlockers(db, task)
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTaskPageSize caps the limit parameter of task listings.
+const maxTaskPageSize = 500
+
+// priorityRank orders priorities from low to high in SQL.
+const priorityRank = "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END"
+
+// taskSortColumns maps the sort parameter to the expression tasks are ordered by.
+var taskSortColumns = map[string]string{
+	"position":   "position",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"due_date":   "due_date",
+	"title":      "title",
+	"priority":   priorityRank,
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// taskCursor marks the last task of a page by its sort value and ID.
+type taskCursor struct {
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+// applyTaskFilters narrows query by the task listing's filter parameters. It
+// writes a 400 response and returns ok false when a parameter is malformed.
+func applyTaskFilters(c *gin.Context, db *gorm.DB, boardID uint, query *gorm.DB) (*gorm.DB, bool) {
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status IN ?", strings.Split(status, ","))
+	}
+	if columnID := c.Query("column_id"); columnID != "" {
+		var column models.Column
+		if err := db.Where("id = ? AND board_id = ?", columnID, boardID).First(&column).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Column not found on this board"})
+			return nil, false
+		}
+		query = query.Where("status = ?", column.Status)
+	}
+	if priority := c.Query("priority"); priority != "" {
+		query = query.Where("priority IN ?", strings.Split(priority, ","))
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if labelID := c.Query("label_id"); labelID != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM task_labels WHERE task_labels.task_id = tasks.id AND task_labels.label_id IN ?)", strings.Split(labelID, ","))
+	}
+	if tag := c.Query("tag"); tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM task_labels JOIN labels ON labels.id = task_labels.label_id WHERE task_labels.task_id = tasks.id AND LOWER(labels.name) = ?)", strings.ToLower(tag))
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + strings.ToLower(q) + "%"
+		query = query.Where("LOWER(title) LIKE ? OR LOWER(description) LIKE ?", pattern, pattern)
+	}
+
+	// Assignees and watchers live in join tables; "none" means no assignee at all
+	for _, filter := range []struct{ param, table string }{{"assignee_id", "task_assignees"}, {"watcher_id", "task_watchers"}} {
+		value := c.Query(filter.param)
+		if value == "" {
+			continue
+		}
+		if value == "none" && filter.param == "assignee_id" {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM task_assignees WHERE task_assignees.task_id = tasks.id)")
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + filter.param})
+			return nil, false
+		}
+		query = query.Where("EXISTS (SELECT 1 FROM "+filter.table+" WHERE "+filter.table+".task_id = tasks.id AND "+filter.table+".user_id = ?)", id)
+	}
+
+	for _, param := range []string{"created_by", "parent_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		if value == "none" && param != "created_by" {
+			query = query.Where(param + " IS NULL")
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+			return nil, false
+		}
+		query = query.Where(param+" = ?", id)
+	}
+
+	dateFilters := []struct{ param, condition string }{
+		{"due_before", "due_date < ?"},
+		{"due_after", "due_date > ?"},
+		{"updated_since", "updated_at >= ?"},
+	}
+	for _, filter := range dateFilters {
+		value := c.Query(filter.param)
+		if value == "" {
+			continue
+		}
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + filter.param + " date"})
+			return nil, false
+		}
+		query = query.Where(filter.condition, t)
+	}
+
+	query, ok := applyCustomFieldFilters(c, db, boardID, query)
+	if !ok {
+		return nil, false
+	}
+
+	if c.Query("overdue") == "true" {
+		done, err := doneStatus(db, boardID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+			return nil, false
+		}
+		query = query.Where("due_date < ? AND status <> ?", time.Now(), done)
+	}
+
+	return query, true
+}
+
+// applyTaskPage orders query by the sort and order parameters and continues
+// after the cursor if one is given. Due dates sort with unset values last.
+func applyTaskPage(query *gorm.DB, sort, order, cursor string) (*gorm.DB, error) {
+	expr := taskSortColumns[sort]
+	direction, op := "asc", ">"
+	if order == "desc" {
+		direction, op = "desc", "<"
+	}
+
+	if cursor != "" {
+		after, err := decodeTaskCursor(sort, cursor)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case after.Value == nil:
+			query = query.Where(expr+" IS NULL AND id "+op+" ?", after.ID)
+		case sort == "due_date":
+			query = query.Where("("+expr+" "+op+" ? OR ("+expr+" = ? AND id "+op+" ?) OR "+expr+" IS NULL)", after.Value, after.Value, after.ID)
+		default:
+			query = query.Where("("+expr+" "+op+" ? OR ("+expr+" = ? AND id "+op+" ?))", after.Value, after.Value, after.ID)
+		}
+	}
+
+	if sort == "due_date" {
+		query = query.Order("due_date IS NULL")
+	}
+	return query.Order(expr + " " + direction).Order("id " + direction), nil
+}
+
+// encodeTaskCursor builds the opaque cursor that resumes a listing after task.
+func encodeTaskCursor(sort string, task *models.Task) string {
+	cursor := taskCursor{ID: task.ID}
+	switch sort {
+	case "created_at":
+		cursor.Value = task.CreatedAt
+	case "updated_at":
+		cursor.Value = task.UpdatedAt
+	case "due_date":
+		if task.DueDate != nil {
+			cursor.Value = *task.DueDate
+		}
+	case "title":
+		cursor.Value = task.Title
+	case "priority":
+		rank := 1
+		switch task.Priority {
+		case "high":
+			rank = 3
+		case "medium":
+			rank = 2
+		}
+		cursor.Value = rank
+	default:
+		cursor.Value = task.Position
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTaskCursor(sort, encoded string) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, errInvalidCursor
+	}
+
+	switch value := cursor.Value.(type) {
+	case nil:
+		if sort != "due_date" {
+			return nil, errInvalidCursor
+		}
+	case string:
+		if sort == "created_at" || sort == "updated_at" || sort == "due_date" {
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, errInvalidCursor
+			}
+			cursor.Value = t
+		} else if sort != "title" {
+			return nil, errInvalidCursor
+		}
+	case float64:
+		if sort != "position" && sort != "priority" {
+			return nil, errInvalidCursor
+		}
+	default:
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// buildTaskResponses converts tasks with preloaded labels into responses. The
+// progress rollups of the whole batch are loaded with a fixed number of queries.
+func buildTaskResponses(db *gorm.DB, tasks []models.Task) ([]models.TaskResponse, error) {
+	responses := make([]models.TaskResponse, 0, len(tasks))
+	if len(tasks) == 0 {
+		return responses, nil
+	}
+
+	ids := make([]uint, len(tasks))
+	doneByBoard := make(map[uint]string)
+	for i, task := range tasks {
+		ids[i] = task.ID
+		if _, ok := doneByBoard[task.BoardID]; !ok {
+			done, err := doneStatus(db, task.BoardID)
+			if err != nil {
+				return nil, err
+			}
+			doneByBoard[task.BoardID] = done
+		}
+	}
+
+	var checklists []struct {
+		TaskID uint
+		Total  int64
+		Done   int64
+	}
+	if err := db.Model(&models.ChecklistItem{}).
+		Select("task_id, COUNT(*) AS total, SUM(CASE WHEN done THEN 1 ELSE 0 END) AS done").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&checklists).Error; err != nil {
+		return nil, err
+	}
+
+	assignees, err := taskUserIDsByTask(db, &models.TaskAssignee{}, ids)
+	if err != nil {
+		return nil, err
+	}
+	watchers, err := taskUserIDsByTask(db, &models.TaskWatcher{}, ids)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := taskCustomFieldValues(db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtasks []struct {
+		ParentID uint
+		BoardID  uint
+		Status   string
+		Total    int64
+	}
+	if err := db.Model(&models.Task{}).
+		Select("parent_id, board_id, status, COUNT(*) AS total").
+		Where("parent_id IN ?", ids).
+		Group("parent_id, board_id, status").
+		Scan(&subtasks).Error; err != nil {
+		return nil, err
+	}
+
+	progress := make(map[uint]*models.TaskProgress, len(tasks))
+	for _, id := range ids {
+		progress[id] = &models.TaskProgress{}
+	}
+	for _, row := range checklists {
+		progress[row.TaskID].ChecklistTotal = row.Total
+		progress[row.TaskID].ChecklistDone = row.Done
+	}
+	for _, row := range subtasks {
+		progress[row.ParentID].SubtasksTotal += row.Total
+		if row.Status == doneByBoard[row.BoardID] {
+			progress[row.ParentID].SubtasksDone += row.Total
+		}
+	}
+
+	now := time.Now()
+	for _, task := range tasks {
+		response := models.TaskResponse{
+			ID:             task.ID,
+			Title:          task.Title,
+			Description:    task.Description,
+			Priority:       task.Priority,
+			Category:       task.Category,
+			Status:         task.Status,
+			Position:       task.Position,
+			BoardID:        task.BoardID,
+			ParentID:       task.ParentID,
+			CreatedBy:      task.CreatedBy,
+			AssigneeID:     task.AssigneeID,
+			AssigneeIDs:    assignees[task.ID],
+			WatcherIDs:     watchers[task.ID],
+			EstimatedHours: task.EstimatedHours,
+			ActualHours:    task.ActualHours,
+			StartDate:      task.StartDate,
+			DueDate:        task.DueDate,
+			Overdue:        task.DueDate != nil && task.DueDate.Before(now) && task.Status != doneByBoard[task.BoardID],
+			CreatedAt:      task.CreatedAt,
+			UpdatedAt:      task.UpdatedAt,
+			Labels:         task.Labels,
+			Tags:           labelNames(task.Labels),
+			Progress:       *progress[task.ID],
+			CustomFields:   customFields[task.ID],
+		}
+		if response.Labels == nil {
+			response.Labels = []models.Label{}
+		}
+		if response.AssigneeIDs == nil {
+			response.AssigneeIDs = []uint{}
+		}
+		if response.WatcherIDs == nil {
+			response.WatcherIDs = []uint{}
+		}
+		if response.CustomFields == nil {
+			response.CustomFields = map[uint]interface{}{}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// taskUserIDsByTask loads the user IDs of the assignee or watcher table model
+// refers to for a batch of tasks, sorted per task.
+func taskUserIDsByTask(db *gorm.DB, model interface{}, taskIDs []uint) (map[uint][]uint, error) {
+	var rows []struct {
+		TaskID uint
+		UserID uint
+	}
+	if err := db.Model(model).Select("task_id, user_id").Where("task_id IN ?", taskIDs).Order("user_id asc").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	byTask := make(map[uint][]uint)
+	for _, row := range rows {
+		byTask[row.TaskID] = append(byTask[row.TaskID], row.UserID)
+	}
+	return byTask, nil
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"kanban-backend/internal/database"
 	"kanban-backend/internal/middleware"
@@ -10,8 +12,22 @@ import (
 	"kanban-backend/internal/websocket"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+const (
+	// taskPositionGap is the spacing between consecutive task ranks in a
+	// column. Moves take the midpoint between neighbours so only the moved
+	// row is written.
+	taskPositionGap = 1024.0
+
+	// minTaskPositionGap is the smallest distance between neighbours before
+	// the column is renumbered with fresh gaps.
+	minTaskPositionGap = 1e-6
+)
+
+var errInvalidNeighbour = errors.New("neighbour task must be on the same board and in the target column")
+
 type TaskHandler struct {
 	hub *websocket.Hub
 }
@@ -39,9 +55,54 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
+	if !checkTaskDates(c, req.StartDate, req.DueDate) {
+		return
+	}
+
 	db := database.GetDB()
+	if !checkTaskStatus(c, db, uint(boardID), req.Status) {
+		return
+	}
+
+	if req.ParentID != nil {
+		if err := validateTaskParent(db, &models.Task{BoardID: uint(boardID)}, *req.ParentID); err != nil {
+			if errors.Is(err, errInvalidParent) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate parent task"})
+			return
+		}
+	}
+
+	assigneeIDs := requestAssignees(req.AssigneeIDs, req.AssigneeID)
+	if !checkBoardMembers(c, db, uint(boardID), assigneeIDs, "assignee") {
+		return
+	}
+	watcherIDs := uniqueUints(req.WatcherIDs)
+	if !checkBoardMembers(c, db, uint(boardID), watcherIDs, "watcher") {
+		return
+	}
+	customFields, customValues, ok := resolveCustomFieldValues(c, db, uint(boardID), req.CustomFields, true)
+	if !ok {
+		return
+	}
+
 	tx := db.Begin()
 
+	wipExceeded, ok := enforceWIPLimit(c, tx, uint(boardID), req.Status, 1)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	position, err := nextTaskPosition(tx, uint(boardID), req.Status, 0)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		return
+	}
+
 	// Create task
 	task := models.Task{
 		Title:          req.Title,
@@ -49,10 +110,13 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		Priority:       req.Priority,
 		Category:       req.Category,
 		Status:         req.Status,
+		Position:       position,
 		BoardID:        uint(boardID),
+		ParentID:       req.ParentID,
 		CreatedBy:      userID,
-		AssigneeID:     req.AssigneeID,
 		EstimatedHours: req.EstimatedHours,
+		StartDate:      req.StartDate,
+		DueDate:        req.DueDate,
 	}
 
 	if err := tx.Create(&task).Error; err != nil {
@@ -61,27 +125,58 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Create tags
-	for _, tag := range req.Tags {
-		taskTag := models.TaskTag{
-			TaskID: task.ID,
-			Tag:    tag,
-		}
-		if err := tx.Create(&taskTag).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task tags"})
-			return
-		}
+	labels, createdLabels, ok := applyTaskLabels(c, tx, &task, req.LabelIDs, req.Tags)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	if err := setTaskAssignees(tx, &task, assigneeIDs); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task"})
+		return
+	}
+	if err := setTaskWatchers(tx, task.ID, watcherIDs); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set task watchers"})
+		return
+	}
+	if err := setTaskCustomFields(tx, task.ID, customValues); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set custom fields"})
+		return
+	}
+
+	changes := snapshotTaskFields(taskFields(&task, labelNames(labels), sortedUints(assigneeIDs)), true)
+	changes = append(changes, customFieldChanges(customFields, nil, customValues)...)
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionCreated, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	automations, err := runAutomations(tx, &task, userID, models.AutomationTriggerTaskCreated, changes)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run automations"})
+		return
 	}
 
 	tx.Commit()
+	automations.send(h.hub)
+
+	for _, label := range createdLabels {
+		h.hub.BroadcastToBoard(task.BoardID, "label_created", label)
+	}
 
 	// Load complete task response
 	var taskResponse models.TaskResponse
 	h.loadTaskResponse(task.ID, &taskResponse)
+	taskResponse.WIPExceeded = wipExceeded
 
 	// Broadcast to board members
 	h.hub.BroadcastToBoard(uint(boardID), "task_created", taskResponse)
+	h.broadcastParentProgress(task.ParentID)
 
 	c.JSON(http.StatusCreated, taskResponse)
 }
@@ -99,17 +194,57 @@ func (h *TaskHandler) GetTasks(c *gin.Context) {
 		return
 	}
 
+	sort := c.DefaultQuery("sort", "position")
+	if _, ok := taskSortColumns[sort]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort field"})
+		return
+	}
+	order := c.DefaultQuery("order", "asc")
+	if order != "asc" && order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+
+	// Without a limit the whole board is returned, as before pagination existed
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 || n > maxTaskPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	db := database.GetDB()
+	query, ok := applyTaskFilters(c, db, uint(boardID), db.Where("board_id = ?", boardID))
+	if !ok {
+		return
+	}
+	query, err = applyTaskPage(query, sort, order, c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	if limit > 0 {
+		query = query.Limit(limit + 1)
+	}
+
 	var tasks []models.Task
-	if err := database.GetDB().Where("board_id = ?", boardID).Find(&tasks).Error; err != nil {
+	if err := query.Preload("Labels").Find(&tasks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
 		return
 	}
 
-	var taskResponses []models.TaskResponse
-	for _, task := range tasks {
-		var taskResponse models.TaskResponse
-		h.loadTaskResponse(task.ID, &taskResponse)
-		taskResponses = append(taskResponses, taskResponse)
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+		c.Header("X-Next-Cursor", encodeTaskCursor(sort, &tasks[limit-1]))
+	}
+
+	taskResponses, err := buildTaskResponses(db, tasks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
 	}
 
 	c.JSON(http.StatusOK, taskResponses)
@@ -164,19 +299,87 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !checkTaskDates(c, req.StartDate, req.DueDate) {
+		return
+	}
 
 	db := database.GetDB()
+
+	// Only a changed status is checked so tasks left orphaned can still be edited
+	wipExceeded := false
+	statusChanged := req.Status != task.Status
+	if statusChanged {
+		if !checkTaskStatus(c, db, task.BoardID, req.Status) {
+			return
+		}
+		if !checkOpenSubtasks(c, db, &task, req.Status) {
+			return
+		}
+		if !checkOpenBlockers(c, db, &task, req.Status) {
+			return
+		}
+	}
+
+	assigneeIDs := requestAssignees(req.AssigneeIDs, req.AssigneeID)
+	if !checkBoardMembers(c, db, task.BoardID, assigneeIDs, "assignee") {
+		return
+	}
+	if !checkBoardMembers(c, db, task.BoardID, uniqueUints(req.WatcherIDs), "watcher") {
+		return
+	}
+	customFields, customValues, ok := resolveCustomFieldValues(c, db, task.BoardID, req.CustomFields, false)
+	if !ok {
+		return
+	}
+
+	oldLabels, err := taskLabelNames(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task labels"})
+		return
+	}
+	oldAssignees, err := taskUserIDs(db, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task assignees"})
+		return
+	}
+	before := taskFields(&task, oldLabels, oldAssignees)
+	oldCustomFields, err := taskCustomFieldValues(db, []uint{task.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load custom fields"})
+		return
+	}
+
 	tx := db.Begin()
 
+	// A status change through a plain update appends the task to its new column
+	if statusChanged {
+		if wipExceeded, ok = enforceWIPLimit(c, tx, task.BoardID, req.Status, 1); !ok {
+			tx.Rollback()
+			return
+		}
+		position, err := nextTaskPosition(tx, task.BoardID, req.Status, task.ID)
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+			return
+		}
+		task.Position = position
+	}
+
 	// Update task
 	task.Title = req.Title
 	task.Description = req.Description
 	task.Priority = req.Priority
 	task.Category = req.Category
 	task.Status = req.Status
-	task.AssigneeID = req.AssigneeID
 	task.EstimatedHours = req.EstimatedHours
-	task.ActualHours = req.ActualHours
+	task.StartDate = req.StartDate
+	if !sameTime(task.DueDate, req.DueDate) {
+		// A new due date gets its own due soon and overdue events
+		task.DueSoonNotifiedAt = nil
+		task.OverdueNotifiedAt = nil
+	}
+	task.DueDate = req.DueDate
 
 	if err := tx.Save(&task).Error; err != nil {
 		tx.Rollback()
@@ -184,34 +387,64 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
-	// Delete existing tags
-	if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskTag{}).Error; err != nil {
+	labels, createdLabels, ok := applyTaskLabels(c, tx, &task, req.LabelIDs, req.Tags)
+	if !ok {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tags"})
 		return
 	}
 
-	// Create new tags
-	for _, tag := range req.Tags {
-		taskTag := models.TaskTag{
-			TaskID: task.ID,
-			Tag:    tag,
-		}
-		if err := tx.Create(&taskTag).Error; err != nil {
+	if err := setTaskAssignees(tx, &task, assigneeIDs); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task"})
+		return
+	}
+	if req.WatcherIDs != nil {
+		if err := setTaskWatchers(tx, task.ID, req.WatcherIDs); err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task tags"})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set task watchers"})
 			return
 		}
 	}
 
+	if err := setTaskCustomFields(tx, task.ID, customValues); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set custom fields"})
+		return
+	}
+
+	changes := diffTaskFields(before, taskFields(&task, labelNames(labels), sortedUints(assigneeIDs)))
+	changes = append(changes, customFieldChanges(customFields, oldCustomFields[task.ID], customValues)...)
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionUpdated, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	automations, err := runAutomations(tx, &task, userID, models.AutomationTriggerTaskUpdated, changes)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run automations"})
+		return
+	}
+
 	tx.Commit()
+	automations.send(h.hub)
+
+	for _, label := range createdLabels {
+		h.hub.BroadcastToBoard(task.BoardID, "label_created", label)
+	}
 
 	// Load complete task response
 	var taskResponse models.TaskResponse
 	h.loadTaskResponse(task.ID, &taskResponse)
+	taskResponse.WIPExceeded = wipExceeded
 
 	// Broadcast to board members
 	h.hub.BroadcastToBoard(task.BoardID, "task_updated", taskResponse)
+	notifyWatchers(h.hub, &task, userID, "task_updated", gin.H{"changes": changes})
+	if statusChanged {
+		h.broadcastParentProgress(task.ParentID)
+	}
 
 	c.JSON(http.StatusOK, taskResponse)
 }
@@ -235,13 +468,39 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := database.GetDB().Delete(&task).Error; err != nil {
+	db := database.GetDB()
+	labels, err := taskLabelNames(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task labels"})
+		return
+	}
+	assignees, err := taskUserIDs(db, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task assignees"})
+		return
+	}
+
+	tx := db.Begin()
+
+	if err := tx.Delete(&task).Error; err != nil {
+		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
 
+	changes := snapshotTaskFields(taskFields(&task, labels, assignees), false)
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionDeleted, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	tx.Commit()
+
 	// Broadcast to board members
 	h.hub.BroadcastToBoard(task.BoardID, "task_deleted", gin.H{"task_id": taskID})
+	notifyWatchers(h.hub, &task, userID, "task_deleted", nil)
+	h.broadcastParentProgress(task.ParentID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
@@ -265,27 +524,103 @@ func (h *TaskHandler) MoveTask(c *gin.Context) {
 		return
 	}
 
-	// Accept any status string to support custom columns
-	var req struct {
-		Status string `json:"status" binding:"required,min=1"`
-	}
+	var req models.MoveTaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	task.Status = req.Status
-	if err := database.GetDB().Save(&task).Error; err != nil {
+	db := database.GetDB()
+
+	// Resolve the target column
+	status := req.Status
+	if req.ColumnID != nil {
+		var column models.Column
+		if err := db.Where("id = ? AND board_id = ?", *req.ColumnID, task.BoardID).First(&column).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Column not found on this board"})
+			return
+		}
+		status = column.Status
+	} else if status == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status or column_id is required"})
+		return
+	} else if !checkTaskStatus(c, db, task.BoardID, status) {
+		return
+	}
+
+	// Reordering within a column never changes its task count
+	wipExceeded := false
+	statusChanged := status != task.Status
+	if statusChanged {
+		if !checkOpenSubtasks(c, db, &task, status) {
+			return
+		}
+		if !checkOpenBlockers(c, db, &task, status) {
+			return
+		}
+	}
+
+	tx := db.Begin()
+
+	if statusChanged {
+		var ok bool
+		if wipExceeded, ok = enforceWIPLimit(c, tx, task.BoardID, status, 1); !ok {
+			tx.Rollback()
+			return
+		}
+	}
+
+	position, err := resolveTaskPosition(tx, &task, status, req.AfterID, req.BeforeID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, errInvalidNeighbour) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task"})
 		return
 	}
 
+	before := taskFields(&task, nil, nil)
+	task.Status = status
+	task.Position = position
+	if err := tx.Save(&task).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task"})
+		return
+	}
+
+	changes := diffTaskFields(before, taskFields(&task, nil, nil))
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionMoved, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	// Reordering within a column is not a move for automations
+	var automations *automationResult
+	if statusChanged {
+		if automations, err = runAutomations(tx, &task, userID, models.AutomationTriggerTaskMoved, changes); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run automations"})
+			return
+		}
+	}
+
+	tx.Commit()
+	automations.send(h.hub)
+
 	// Load complete task response
 	var taskResponse models.TaskResponse
 	h.loadTaskResponse(task.ID, &taskResponse)
+	taskResponse.WIPExceeded = wipExceeded
 
 	// Broadcast to board members
 	h.hub.BroadcastToBoard(task.BoardID, "task_moved", taskResponse)
+	notifyWatchers(h.hub, &task, userID, "task_moved", gin.H{"changes": changes})
+	if statusChanged {
+		h.broadcastParentProgress(task.ParentID)
+	}
 
 	c.JSON(http.StatusOK, taskResponse)
 }
@@ -293,7 +628,11 @@ func (h *TaskHandler) MoveTask(c *gin.Context) {
 // Helper functions
 func (h *TaskHandler) hasAccess(boardID, userID uint) bool {
 	var count int64
-	database.GetDB().Model(&models.BoardMember{}).Where("board_id = ? AND user_id = ?", boardID, userID).Count(&count)
+	database.GetDB().Model(&models.BoardMember{}).
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
+		Where("board_members.board_id = ? AND board_members.user_id = ?", boardID, userID).
+		Where(twoFactorMet, true).
+		Count(&count)
 	return count > 0
 }
 
@@ -301,35 +640,187 @@ func (h *TaskHandler) hasPermission(boardID, userID uint, action string) bool {
 	var count int64
 	database.GetDB().Table("board_members").
 		Joins("JOIN member_permissions ON board_members.id = member_permissions.member_id").
+		Joins("JOIN boards ON boards.id = board_members.board_id AND boards.deleted_at IS NULL").
 		Where("board_members.board_id = ? AND board_members.user_id = ? AND member_permissions.action = ? AND member_permissions.granted = ?", boardID, userID, action, true).
+		Where(twoFactorMet, true).
 		Count(&count)
 	return count > 0
 }
 
 func (h *TaskHandler) loadTaskResponse(taskID uint, response *models.TaskResponse) error {
-	var task models.Task
-	if err := database.GetDB().Preload("Tags").First(&task, taskID).Error; err != nil {
+	loaded, err := LoadTaskResponse(taskID)
+	if err != nil {
 		return err
 	}
+	*response = loaded
 
-	response.ID = task.ID
-	response.Title = task.Title
-	response.Description = task.Description
-	response.Priority = task.Priority
-	response.Category = task.Category
-	response.Status = task.Status
-	response.BoardID = task.BoardID
-	response.CreatedBy = task.CreatedBy
-	response.AssigneeID = task.AssigneeID
-	response.EstimatedHours = task.EstimatedHours
-	response.ActualHours = task.ActualHours
-	response.CreatedAt = task.CreatedAt
-	response.UpdatedAt = task.UpdatedAt
+	return nil
+}
 
-	// Load tags
-	for _, tag := range task.Tags {
-		response.Tags = append(response.Tags, tag.Tag)
+// LoadTaskResponse builds the API representation of a task. Background jobs
+// use it to broadcast the tasks they create.
+func LoadTaskResponse(taskID uint) (models.TaskResponse, error) {
+	var task models.Task
+	if err := database.GetDB().Preload("Labels").First(&task, taskID).Error; err != nil {
+		return models.TaskResponse{}, err
 	}
 
-	return nil
-}
\ No newline at end of file
+	responses, err := buildTaskResponses(database.GetDB(), []models.Task{task})
+	if err != nil {
+		return models.TaskResponse{}, err
+	}
+	return responses[0], nil
+}
+
+// checkTaskDates rejects a start date that falls after the due date.
+func checkTaskDates(c *gin.Context, startDate, dueDate *time.Time) bool {
+	if startDate != nil && dueDate != nil && startDate.After(*dueDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date must not be after due_date"})
+		return false
+	}
+	return true
+}
+
+// sameTime reports whether two optional timestamps are both unset or equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// parseDateParam accepts an RFC 3339 timestamp or a plain YYYY-MM-DD date.
+func parseDateParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// checkTaskStatus reports whether status belongs to one of the board's
+// columns, writing a 400 response listing the allowed statuses if not.
+func checkTaskStatus(c *gin.Context, db *gorm.DB, boardID uint, status string) bool {
+	statuses, err := boardStatuses(db, boardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch columns"})
+		return false
+	}
+	if !containsStatus(statuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            "Unknown status for this board",
+			"status":           status,
+			"allowed_statuses": statuses,
+		})
+		return false
+	}
+	return true
+}
+
+// nextTaskPosition returns a rank that places a task at the end of the given
+// column. excludeID lets a task that is already in the column be skipped.
+func nextTaskPosition(tx *gorm.DB, boardID uint, status string, excludeID uint) (float64, error) {
+	var maxPos float64
+	err := tx.Model(&models.Task{}).
+		Where("board_id = ? AND status = ? AND id <> ?", boardID, status, excludeID).
+		Select("COALESCE(MAX(position),0)").
+		Scan(&maxPos).Error
+	return maxPos + taskPositionGap, err
+}
+
+// resolveTaskPosition computes the rank for task when it is dropped into the
+// status column between afterID and beforeID. Either neighbour may be nil; the
+// missing one is looked up from the column so only the moved row changes.
+func resolveTaskPosition(tx *gorm.DB, task *models.Task, status string, afterID, beforeID *uint) (float64, error) {
+	if afterID == nil && beforeID == nil {
+		return nextTaskPosition(tx, task.BoardID, status, task.ID)
+	}
+
+	column := func() *gorm.DB {
+		return tx.Model(&models.Task{}).Where("board_id = ? AND status = ? AND id <> ?", task.BoardID, status, task.ID)
+	}
+	loadNeighbour := func(id uint) (*models.Task, error) {
+		var neighbour models.Task
+		if err := column().Where("id = ?", id).First(&neighbour).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errInvalidNeighbour
+			}
+			return nil, err
+		}
+		return &neighbour, nil
+	}
+
+	var prev, next *models.Task
+	var err error
+	if afterID != nil {
+		if prev, err = loadNeighbour(*afterID); err != nil {
+			return 0, err
+		}
+	}
+	if beforeID != nil {
+		if next, err = loadNeighbour(*beforeID); err != nil {
+			return 0, err
+		}
+	}
+
+	// Fill in the missing neighbour from the column order
+	if next == nil {
+		var successor models.Task
+		err := column().
+			Where("position > ? OR (position = ? AND id > ?)", prev.Position, prev.Position, prev.ID).
+			Order("position asc, id asc").
+			First(&successor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return prev.Position + taskPositionGap, nil
+		} else if err != nil {
+			return 0, err
+		}
+		next = &successor
+	}
+	if prev == nil {
+		var predecessor models.Task
+		err := column().
+			Where("position < ? OR (position = ? AND id < ?)", next.Position, next.Position, next.ID).
+			Order("position desc, id desc").
+			First(&predecessor).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return next.Position - taskPositionGap, nil
+		} else if err != nil {
+			return 0, err
+		}
+		prev = &predecessor
+	}
+
+	if next.Position-prev.Position < minTaskPositionGap {
+		positions, err := rebalanceTaskPositions(tx, task.BoardID, status, task.ID)
+		if err != nil {
+			return 0, err
+		}
+		prev.Position, next.Position = positions[prev.ID], positions[next.ID]
+		if next.Position <= prev.Position {
+			return 0, errInvalidNeighbour
+		}
+	}
+
+	return (prev.Position + next.Position) / 2, nil
+}
+
+// rebalanceTaskPositions renumbers a column with evenly spaced ranks, keeping
+// its current order, and returns the new rank of every task it touched.
+func rebalanceTaskPositions(tx *gorm.DB, boardID uint, status string, excludeID uint) (map[uint]float64, error) {
+	var tasks []models.Task
+	if err := tx.Where("board_id = ? AND status = ? AND id <> ?", boardID, status, excludeID).
+		Order("position asc, id asc").
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	positions := make(map[uint]float64, len(tasks))
+	for i, t := range tasks {
+		position := float64(i+1) * taskPositionGap
+		if err := tx.Model(&models.Task{}).Where("id = ?", t.ID).UpdateColumn("position", position).Error; err != nil {
+			return nil, err
+		}
+		positions[t.ID] = position
+	}
+	return positions, nil
+}
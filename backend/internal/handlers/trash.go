@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/jobs"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeletedBoards lists the caller's boards that are in the trash.
+func (h *BoardHandler) GetDeletedBoards(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var boards []models.Board
+	if err := database.GetDB().Unscoped().
+		Where("created_by = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at desc").
+		Find(&boards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	retention := jobs.TrashRetention()
+	items := make([]models.TrashItem, 0, len(boards))
+	for _, board := range boards {
+		items = append(items, models.TrashItem{
+			Type:      "board",
+			ID:        board.ID,
+			BoardID:   board.ID,
+			Title:     board.Title,
+			DeletedAt: board.DeletedAt.Time,
+			PurgeAt:   board.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// GetBoardTrash lists the deleted columns and tasks of a board, newest first.
+func (h *BoardHandler) GetBoardTrash(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasAccess(uint(boardID), userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var columns []models.Column
+	if err := db.Unscoped().Where("board_id = ? AND deleted_at IS NOT NULL", boardID).Order("deleted_at desc").Find(&columns).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	var tasks []models.Task
+	if err := db.Unscoped().Where("board_id = ? AND deleted_at IS NOT NULL", boardID).Order("deleted_at desc").Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	retention := jobs.TrashRetention()
+	items := make([]models.TrashItem, 0, len(columns)+len(tasks))
+	for _, column := range columns {
+		items = append(items, models.TrashItem{
+			Type:      "column",
+			ID:        column.ID,
+			BoardID:   column.BoardID,
+			Title:     column.Title,
+			Status:    column.Status,
+			DeletedAt: column.DeletedAt.Time,
+			PurgeAt:   column.DeletedAt.Time.Add(retention),
+		})
+	}
+	for _, task := range tasks {
+		items = append(items, models.TrashItem{
+			Type:      "task",
+			ID:        task.ID,
+			BoardID:   task.BoardID,
+			Title:     task.Title,
+			Status:    task.Status,
+			DeletedAt: task.DeletedAt.Time,
+			PurgeAt:   task.DeletedAt.Time.Add(retention),
+		})
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// RestoreBoard brings a trashed board back together with its columns, tasks
+// and members. Only the owner can restore a board.
+func (h *BoardHandler) RestoreBoard(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var board models.Board
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", boardID).First(&board).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Board not found in trash"})
+		return
+	}
+
+	if board.CreatedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only board owner can restore the board"})
+		return
+	}
+
+	if err := db.Unscoped().Model(&board).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore board"})
+		return
+	}
+
+	var boardResponse models.BoardResponse
+	h.loadBoardResponse(board.ID, &boardResponse)
+
+	h.hub.BroadcastToBoard(board.ID, "board_restored", boardResponse)
+
+	c.JSON(http.StatusOK, boardResponse)
+}
+
+// RestoreColumn brings a trashed column back at the end of the board. It
+// fails with 409 when a live column already uses the same status.
+func (h *ColumnHandler) RestoreColumn(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+	columnID, err := strconv.ParseUint(c.Param("colId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid column ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var column models.Column
+	if err := db.Unscoped().Where("id = ? AND board_id = ? AND deleted_at IS NOT NULL", columnID, boardID).First(&column).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Column not found in trash"})
+		return
+	}
+
+	var conflicts int64
+	db.Model(&models.Column{}).Where("board_id = ? AND status = ?", boardID, column.Status).Count(&conflicts)
+	if conflicts > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A column with this status already exists"})
+		return
+	}
+
+	var maxPos int
+	db.Model(&models.Column{}).Where("board_id = ?", boardID).Select("COALESCE(MAX(position),0)").Scan(&maxPos)
+
+	if err := db.Unscoped().Model(&column).Updates(map[string]interface{}{"deleted_at": nil, "position": maxPos + 1}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore column"})
+		return
+	}
+	column.DeletedAt.Valid = false
+
+	h.hub.BroadcastToBoard(uint(boardID), "column_restored", column)
+
+	c.JSON(http.StatusOK, column)
+}
+
+// RestoreTask brings a trashed task back to the end of its column. When the
+// column no longer exists the task lands in the board's first column.
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	db := database.GetDB()
+
+	var task models.Task
+	if err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found in trash"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !h.hasPermission(task.BoardID, userID, "delete_task") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	statuses, err := boardStatuses(db, task.BoardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load board columns"})
+		return
+	}
+	status := task.Status
+	if !containsStatus(statuses, status) {
+		status = statuses[0]
+	}
+
+	labels, err := taskLabelNames(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task labels"})
+		return
+	}
+	assignees, err := taskUserIDs(db, &models.TaskAssignee{}, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task assignees"})
+		return
+	}
+
+	tx := db.Begin()
+
+	wipExceeded, ok := enforceWIPLimit(c, tx, task.BoardID, status, 1)
+	if !ok {
+		tx.Rollback()
+		return
+	}
+
+	position, err := nextTaskPosition(tx, task.BoardID, status, task.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task"})
+		return
+	}
+
+	if err := tx.Unscoped().Model(&task).Updates(map[string]interface{}{"deleted_at": nil, "status": status, "position": position}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore task"})
+		return
+	}
+	task.Status = status
+	task.Position = position
+
+	changes := snapshotTaskFields(taskFields(&task, labels, assignees), true)
+	if err := recordTaskActivity(tx, &task, userID, models.TaskActionRestored, models.ActivitySourceREST, changes); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record task activity"})
+		return
+	}
+
+	tx.Commit()
+
+	var taskResponse models.TaskResponse
+	h.loadTaskResponse(task.ID, &taskResponse)
+	taskResponse.WIPExceeded = wipExceeded
+
+	h.hub.BroadcastToBoard(task.BoardID, "task_restored", taskResponse)
+	notifyWatchers(h.hub, &task, userID, "task_restored", nil)
+	h.broadcastParentProgress(task.ParentID)
+
+	c.JSON(http.StatusOK, taskResponse)
+}
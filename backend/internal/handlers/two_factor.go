@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/totp"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	loginChallengeTTL         = 5 * time.Minute
+	maxLoginChallengeAttempts = 5
+
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789" // 32 characters, without o, 1, l and i
+)
+
+// twoFactorMet restricts a board_members query to memberships that satisfy
+// the board's 2FA requirement: the board does not require it, or the member
+// has it enabled. Pass true as its argument.
+const twoFactorMet = `(NOT EXISTS (SELECT 1 FROM board_settings WHERE board_settings.board_id = board_members.board_id AND board_settings.require_two_factor = ?)
+	OR EXISTS (SELECT 1 FROM user_two_factors WHERE user_two_factors.user_id = board_members.user_id AND user_two_factors.enabled_at IS NOT NULL))`
+
+// twoFactorIssuer is the name authenticator apps list the account under.
+func twoFactorIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "TaskflowAI"
+}
+
+// enabledTwoFactor returns the user's 2FA settings if 2FA is on, and nil if not.
+func enabledTwoFactor(db *gorm.DB, userID uint) (*models.UserTwoFactor, error) {
+	var twoFactor models.UserTwoFactor
+	err := db.Where("user_id = ? AND enabled_at IS NOT NULL", userID).First(&twoFactor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// completeLogin finishes a sign-in whose first factor checked out. Users with
+// 2FA get a short-lived challenge to answer at /auth/2fa/verify instead of a
+// session.
+func completeLogin(c *gin.Context, status int, user *models.User) {
+	db := database.GetDB()
+	twoFactor, err := enabledTwoFactor(db, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	if twoFactor == nil {
+		startSession(c, status, user)
+		return
+	}
+
+	token, hash, err := auth.NewLoginChallengeToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+	now := time.Now().UTC()
+	if err := db.Where("user_id = ? AND expires_at < ?", user.ID, now).Delete(&models.LoginChallenge{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+	challenge := models.LoginChallenge{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(loginChallengeTTL),
+	}
+	if err := db.Create(&challenge).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"mfa_required": true,
+		"mfa_token":    token,
+		"expires_in":   int(loginChallengeTTL.Seconds()),
+	})
+}
+
+// VerifyLogin answers a login challenge with a TOTP or recovery code and
+// starts the session.
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	var req models.VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	now := time.Now().UTC()
+
+	var challenge models.LoginChallenge
+	if err := db.Where("token_hash = ?", auth.HashLoginChallengeToken(req.MFAToken)).First(&challenge).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in attempt"})
+		return
+	}
+	if !now.Before(challenge.ExpiresAt) || challenge.Attempts >= maxLoginChallengeAttempts {
+		db.Delete(&challenge)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in attempt"})
+		return
+	}
+
+	// Count the attempt before checking the code, so parallel guesses cannot
+	// exceed the limit
+	result := db.Model(&models.LoginChallenge{}).
+		Where("id = ? AND attempts < ?", challenge.ID, maxLoginChallengeAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in attempt"})
+		return
+	}
+
+	twoFactor, err := enabledTwoFactor(db, challenge.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if twoFactor == nil {
+		// 2FA was turned off since the password was checked
+		db.Delete(&challenge)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in attempt"})
+		return
+	}
+
+	ok, err := checkSecondFactor(db, twoFactor, req.Code, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code", "attempts_left": maxLoginChallengeAttempts - challenge.Attempts - 1})
+		return
+	}
+
+	// Deleting the challenge is what uses it up; only one request gets a session
+	result = db.Delete(&challenge)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in attempt"})
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, challenge.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	startSession(c, http.StatusOK, &user)
+}
+
+// GetTwoFactor reports whether the user has 2FA on and how many recovery codes
+// are left.
+func (h *AuthHandler) GetTwoFactor(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	twoFactor, err := enabledTwoFactor(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor status"})
+		return
+	}
+	if twoFactor == nil {
+		c.JSON(http.StatusOK, gin.H{"enabled": false})
+		return
+	}
+
+	var remaining int64
+	if err := db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch two-factor status"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  true,
+		"enabled_at":               twoFactor.EnabledAt,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// SetupTwoFactor generates a new TOTP secret for the user to add to an
+// authenticator app. 2FA stays off until EnableTwoFactor gets a code for it.
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+
+	var twoFactor models.UserTwoFactor
+	err = db.Where("user_id = ?", userID).First(&twoFactor).Error
+	switch {
+	case err == nil && twoFactor.EnabledAt != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	case err == nil:
+		twoFactor.Secret = secret
+		twoFactor.LastUsedStep = 0
+		err = db.Save(&twoFactor).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		twoFactor = models.UserTwoFactor{UserID: userID, Secret: secret}
+		err = db.Create(&twoFactor).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(twoFactorIssuer(), user.Email, secret),
+	})
+}
+
+// EnableTwoFactor turns 2FA on once the user proves their authenticator works,
+// and returns the recovery codes. They are shown this one time only.
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var twoFactor models.UserTwoFactor
+	if err := db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set up two-factor authentication first"})
+		return
+	}
+	if twoFactor.EnabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, normalizeTOTPCode(req.Code), time.Now())
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		result := tx.Model(&models.UserTwoFactor{}).
+			Where("id = ? AND enabled_at IS NULL", twoFactor.ID).
+			Updates(map[string]interface{}{"enabled_at": now, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTwoFactorChanged
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if errors.Is(err, errTwoFactorChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": true, "recovery_codes": codes})
+}
+
+// DisableTwoFactor turns 2FA off. It takes the password and a TOTP or
+// recovery code; accounts created through SSO have no password of their own
+// and only need the code.
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var identities int64
+	if err := db.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	if identities == 0 || req.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid password"})
+			return
+		}
+	}
+
+	twoFactor, err := enabledTwoFactor(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	if twoFactor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	ok, err := checkSecondFactor(db, twoFactor, req.Code, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.LoginChallenge{}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"enabled": false})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, used or not, with new
+// ones. It takes a TOTP code, not a recovery code.
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	twoFactor, err := enabledTwoFactor(db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+	if twoFactor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+
+	ok, err := checkSecondFactor(db, twoFactor, req.Code, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+var errTwoFactorChanged = errors.New("two-factor settings changed concurrently")
+
+// checkSecondFactor reports whether code is a current TOTP code or, if
+// allowRecovery is set, an unused recovery code. Either is used up by a
+// successful check.
+func checkSecondFactor(db *gorm.DB, twoFactor *models.UserTwoFactor, code string, allowRecovery bool) (bool, error) {
+	if totpCode := normalizeTOTPCode(code); len(totpCode) == totp.Digits {
+		step, ok := totp.Validate(twoFactor.Secret, totpCode, time.Now())
+		if !ok || step <= twoFactor.LastUsedStep {
+			return false, nil
+		}
+		// The step condition makes a code work once, even across parallel requests
+		result := db.Model(&models.UserTwoFactor{}).
+			Where("id = ? AND last_used_step < ?", twoFactor.ID, step).
+			Update("last_used_step", step)
+		return result.RowsAffected == 1, result.Error
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	recoveryCode := normalizeRecoveryCode(code)
+	if len(recoveryCode) != recoveryCodeLength {
+		return false, nil
+	}
+	var unused []models.RecoveryCode
+	if err := db.Where("user_id = ? AND used_at IS NULL", twoFactor.UserID).Find(&unused).Error; err != nil {
+		return false, err
+	}
+	for _, stored := range unused {
+		if bcrypt.CompareHashAndPassword([]byte(stored.CodeHash), []byte(recoveryCode)) != nil {
+			continue
+		}
+		result := db.Model(&models.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", time.Now().UTC())
+		return result.RowsAffected == 1, result.Error
+	}
+	return false, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores new ones,
+// returning them formatted for display.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Create(&models.RecoveryCode{UserID: userID, CodeHash: string(hash)}).Error; err != nil {
+			return nil, err
+		}
+		codes = append(codes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return codes, nil
+}
+
+func newRecoveryCode() (string, error) {
+	raw := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := make([]byte, recoveryCodeLength)
+	for i, b := range raw {
+		code[i] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// normalizeTOTPCode drops the spaces authenticator apps show codes with.
+func normalizeTOTPCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
+
+// normalizeRecoveryCode accepts recovery codes with or without the dash and
+// in any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
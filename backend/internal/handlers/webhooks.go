@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/jobs"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler manages a board's outgoing webhooks. Webhook URLs, secrets
+// and deliveries are only visible to members who hold manage_board.
+type WebhookHandler struct{}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{}
+}
+
+// GetWebhooks returns a board's webhooks.
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	webhooks := []models.Webhook{}
+	if err := database.GetDB().Where("board_id = ?", boardID).Order("id asc").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook adds a webhook. The response carries its signing secret,
+// which is not shown again.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkWebhookURL(c, req.URL) || !checkWebhookEvents(c, req.Events) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = generateWebhookSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+			return
+		}
+	}
+
+	webhook := models.Webhook{
+		BoardID:   uint(boardID),
+		Name:      req.Name,
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    uniqueStrings(req.Events),
+		Enabled:   true,
+		CreatedBy: userID,
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	if err := database.GetDB().Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.WebhookSecretResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// UpdateWebhook changes the URL, events, secret or state of a webhook.
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := loadBoardWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		webhook.Name = *req.Name
+	}
+	if req.URL != nil {
+		if !checkWebhookURL(c, *req.URL) {
+			return
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+	if req.Events != nil {
+		if !checkWebhookEvents(c, req.Events) {
+			return
+		}
+		webhook.Events = uniqueStrings(req.Events)
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+
+	if err := database.GetDB().Save(webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes a webhook and its deliveries, pending ones included.
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := loadBoardWebhook(c)
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// PingWebhook queues a ping event to test a webhook.
+func (h *WebhookHandler) PingWebhook(c *gin.Context) {
+	webhook, ok := loadBoardWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := jobs.QueueWebhookDelivery(database.GetDB(), webhook, models.WebhookEventPing, gin.H{"webhook_id": webhook.ID}, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue ping"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// GetWebhookDeliveries returns the delivery log of a webhook, newest first.
+// Pass status to only list pending, succeeded or failed deliveries.
+func (h *WebhookHandler) GetWebhookDeliveries(c *gin.Context) {
+	webhook, ok := loadBoardWebhook(c)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = n
+	}
+
+	query := database.GetDB().Where("webhook_id = ?", webhook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Order("id desc").Limit(limit).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhookDelivery queues the payload of a delivery again as a new
+// delivery, whatever became of the original.
+func (h *WebhookHandler) RedeliverWebhookDelivery(c *gin.Context) {
+	webhook, ok := loadBoardWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(c.Param("deliveryId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	db := database.GetDB()
+	var delivery models.WebhookDelivery
+	if err := db.Where("id = ? AND webhook_id = ?", deliveryID, webhook.ID).First(&delivery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	redelivery, err := jobs.RequeueWebhookDelivery(db, webhook, &delivery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, redelivery)
+}
+
+// loadBoardWebhook loads the :webhookId webhook of the :id board for a
+// caller holding manage_board. It writes the error response and returns ok
+// false on failure.
+func loadBoardWebhook(c *gin.Context) (*models.Webhook, bool) {
+	boardID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid board ID"})
+		return nil, false
+	}
+	webhookID, err := strconv.ParseUint(c.Param("webhookId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if !hasPermissionOnBoard(uint(boardID), userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+
+	var webhook models.Webhook
+	if err := database.GetDB().Where("id = ? AND board_id = ?", webhookID, boardID).First(&webhook).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return nil, false
+	}
+	return &webhook, true
+}
+
+// checkWebhookURL writes a 400 response and returns false unless rawURL is an
+// absolute http or https URL.
+func checkWebhookURL(c *gin.Context, rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Webhook URL must be an http or https URL"})
+		return false
+	}
+	return true
+}
+
+// checkWebhookEvents writes a 400 response and returns false unless every
+// event is a known board event or "*".
+func checkWebhookEvents(c *gin.Context, events []string) bool {
+	for _, event := range events {
+		if event != models.WebhookEventAll && !containsString(models.WebhookEvents, event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event " + event, "events": models.WebhookEvents})
+			return false
+		}
+	}
+	return true
+}
+
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func uniqueStrings(values []string) []string {
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !containsString(unique, v) {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
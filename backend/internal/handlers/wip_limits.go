@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"kanban-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// checkWIPLimit returns the violation when adding tasks to the status column
+// would exceed its WIP limit, or nil when the column has room or no limit.
+// Call it in the transaction that adds the tasks: on Postgres the column row
+// stays locked until it ends, so concurrent additions are counted one after
+// the other. SQLite allows one writer at a time anyway.
+func checkWIPLimit(db *gorm.DB, boardID uint, status string, adding int) (*models.WIPLimitError, error) {
+	query := db.Where("board_id = ? AND status = ?", boardID, status)
+	if db.Dialector.Name() == "postgres" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var column models.Column
+	err := query.First(&column).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if column.WIPLimit == nil {
+		return nil, nil
+	}
+
+	var count int64
+	if err := db.Model(&models.Task{}).Where("board_id = ? AND status = ?", boardID, status).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count+int64(adding) <= int64(*column.WIPLimit) {
+		return nil, nil
+	}
+
+	return &models.WIPLimitError{
+		Error:     "WIP limit exceeded",
+		Code:      "wip_limit_exceeded",
+		ColumnID:  column.ID,
+		Status:    column.Status,
+		WIPLimit:  *column.WIPLimit,
+		TaskCount: count,
+		Adding:    adding,
+	}, nil
+}
+
+// wipWarnOnly reports whether the board only warns about WIP limits instead
+// of rejecting changes.
+func wipWarnOnly(db *gorm.DB, boardID uint) bool {
+	var settings models.BoardSettings
+	if err := db.Where("board_id = ?", boardID).First(&settings).Error; err != nil {
+		return false
+	}
+	return settings.WIPEnforcement == "warn"
+}
+
+// enforceWIPLimit checks the status column's limit before adding tasks to it,
+// in the transaction tx that adds them. When the board enforces limits
+// strictly it writes a 409 and returns ok false. In warn-only mode the change
+// is allowed and exceeded reports whether the column ends up over its limit.
+func enforceWIPLimit(c *gin.Context, tx *gorm.DB, boardID uint, status string, adding int) (exceeded bool, ok bool) {
+	violation, err := checkWIPLimit(tx, boardID, status, adding)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check WIP limit"})
+		return false, false
+	}
+	if violation == nil {
+		return false, true
+	}
+	if wipWarnOnly(tx, boardID) {
+		return true, true
+	}
+	c.JSON(http.StatusConflict, violation)
+	return true, false
+}
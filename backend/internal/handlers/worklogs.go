@@ -0,0 +1,635 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/middleware"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxTimesheetDays caps the range of a timesheet.
+const maxTimesheetDays = 366
+
+type WorklogHandler struct {
+	hub *websocket.Hub
+}
+
+func NewWorklogHandler(hub *websocket.Hub) *WorklogHandler {
+	return &WorklogHandler{hub: hub}
+}
+
+// GetWorklogs returns a task's worklogs, newest first, including running
+// timers.
+func (h *WorklogHandler) GetWorklogs(c *gin.Context) {
+	task, ok := loadWorklogTask(c, "")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+
+	var worklogs []models.Worklog
+	if err := db.Where("task_id = ?", task.ID).Order("started_at desc, id desc").Find(&worklogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch worklogs"})
+		return
+	}
+
+	responses, err := worklogResponses(db, worklogs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch worklogs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateWorklog logs time the caller spent on a task and adds it to the
+// task's actual hours. Requires edit_task.
+func (h *WorklogHandler) CreateWorklog(c *gin.Context) {
+	task, ok := loadWorklogTask(c, "edit_task")
+	if !ok {
+		return
+	}
+
+	var req models.WorklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	endedAt, ok := worklogEnd(c, &req)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	worklog := models.Worklog{
+		TaskID:    task.ID,
+		BoardID:   task.BoardID,
+		UserID:    userID,
+		StartedAt: req.StartedAt,
+		EndedAt:   &endedAt,
+		Seconds:   int64(endedAt.Sub(req.StartedAt).Seconds()),
+		Note:      req.Note,
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&worklog).Error; err != nil {
+			return err
+		}
+		return syncActualHours(tx, task, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create worklog"})
+		return
+	}
+
+	response := h.broadcastWorklog(task, "worklog_created", &worklog)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateWorklog changes the period or note of a finished worklog. Members
+// edit their own worklogs; manage_board may edit anyone's.
+func (h *WorklogHandler) UpdateWorklog(c *gin.Context) {
+	task, worklog, ok := loadTaskWorklog(c)
+	if !ok {
+		return
+	}
+	if worklog.EndedAt == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Stop the timer before editing it"})
+		return
+	}
+
+	var req models.WorklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	endedAt, ok := worklogEnd(c, &req)
+	if !ok {
+		return
+	}
+
+	worklog.StartedAt = req.StartedAt
+	worklog.EndedAt = &endedAt
+	worklog.Seconds = int64(endedAt.Sub(req.StartedAt).Seconds())
+	worklog.Note = req.Note
+
+	userID := middleware.GetUserID(c)
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(worklog).Error; err != nil {
+			return err
+		}
+		return syncActualHours(tx, task, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update worklog"})
+		return
+	}
+
+	response := h.broadcastWorklog(task, "worklog_updated", worklog)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWorklog removes a worklog, or discards a running timer. Members
+// delete their own worklogs; manage_board may delete anyone's.
+func (h *WorklogHandler) DeleteWorklog(c *gin.Context) {
+	task, worklog, ok := loadTaskWorklog(c)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(worklog).Error; err != nil {
+			return err
+		}
+		return syncActualHours(tx, task, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete worklog"})
+		return
+	}
+
+	h.hub.BroadcastToBoard(task.BoardID, "worklog_deleted", gin.H{
+		"id":           worklog.ID,
+		"task_id":      task.ID,
+		"actual_hours": task.ActualHours,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Worklog deleted successfully"})
+}
+
+// StartTimer starts the caller's timer on a task. The timer is stored as an
+// open worklog, so it keeps running across reconnects until stopped.
+func (h *WorklogHandler) StartTimer(c *gin.Context) {
+	task, ok := loadWorklogTask(c, "edit_task")
+	if !ok {
+		return
+	}
+
+	var req models.TimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	db := database.GetDB()
+	userID := middleware.GetUserID(c)
+
+	running, err := runningTimer(db, task.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start timer"})
+		return
+	}
+	if running != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A timer is already running on this task", "worklog_id": running.ID})
+		return
+	}
+
+	worklog := models.Worklog{
+		TaskID:    task.ID,
+		BoardID:   task.BoardID,
+		UserID:    userID,
+		StartedAt: time.Now(),
+		Note:      req.Note,
+	}
+	if err := db.Create(&worklog).Error; err != nil {
+		// idx_worklogs_running rejects a timer started concurrently
+		if running, _ := runningTimer(db, task.ID, userID); running != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "A timer is already running on this task", "worklog_id": running.ID})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start timer"})
+		return
+	}
+
+	response := h.broadcastWorklog(task, "timer_started", &worklog)
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// StopTimer stops the caller's timer on a task and adds the elapsed time to
+// the task's actual hours. A note replaces the one given at start.
+func (h *WorklogHandler) StopTimer(c *gin.Context) {
+	task, ok := loadWorklogTask(c, "edit_task")
+	if !ok {
+		return
+	}
+
+	var req models.TimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	db := database.GetDB()
+	userID := middleware.GetUserID(c)
+
+	worklog, err := runningTimer(db, task.ID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer"})
+		return
+	}
+	if worklog == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No timer running on this task"})
+		return
+	}
+
+	now := time.Now()
+	worklog.EndedAt = &now
+	worklog.Seconds = int64(now.Sub(worklog.StartedAt).Seconds())
+	if req.Note != "" {
+		worklog.Note = req.Note
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(worklog).Error; err != nil {
+			return err
+		}
+		return syncActualHours(tx, task, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer"})
+		return
+	}
+
+	response := h.broadcastWorklog(task, "timer_stopped", worklog)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRunningTimers returns the caller's running timers across all boards, so
+// a client can pick them up again after reconnecting.
+func (h *WorklogHandler) GetRunningTimers(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var worklogs []models.Worklog
+	if err := db.Joins("JOIN tasks ON tasks.id = worklogs.task_id AND tasks.deleted_at IS NULL").
+		Where("worklogs.user_id = ? AND worklogs.ended_at IS NULL", userID).
+		Order("worklogs.started_at asc").
+		Find(&worklogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timers"})
+		return
+	}
+
+	responses, err := worklogResponses(db, worklogs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// GetTimesheet sums the caller's finished worklogs on every board by day,
+// board and task. from and to take RFC 3339 timestamps or plain dates, a
+// plain to date including that whole day. Without from, the current week
+// (Monday to Monday, UTC) is shown; without to, the seven days after from.
+func (h *WorklogHandler) GetTimesheet(c *gin.Context) {
+	now := time.Now().UTC()
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	from := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+
+	if value := c.Query("from"); value != "" {
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+			return
+		}
+		from = t
+	}
+	to := from.AddDate(0, 0, 7)
+	if value := c.Query("to"); value != "" {
+		t, err := parseDateParam(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+			return
+		}
+		if len(value) == len("2006-01-02") {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be after from"})
+		return
+	}
+	if to.Sub(from) > maxTimesheetDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A timesheet covers at most 366 days"})
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	db := database.GetDB()
+
+	var worklogs []models.Worklog
+	if err := db.Where("user_id = ? AND ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", userID, from, to).
+		Order("started_at asc, id asc").
+		Find(&worklogs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch worklogs"})
+		return
+	}
+
+	responses, err := worklogResponses(db, worklogs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch worklogs"})
+		return
+	}
+
+	var total int64
+	dayTotals := make(map[string]int64)
+	boardTotals := make(map[uint]int64)
+	taskTotals := make(map[uint]int64)
+	taskBoards := make(map[uint]uint)
+	for _, worklog := range worklogs {
+		total += worklog.Seconds
+		dayTotals[worklog.StartedAt.UTC().Format("2006-01-02")] += worklog.Seconds
+		boardTotals[worklog.BoardID] += worklog.Seconds
+		taskTotals[worklog.TaskID] += worklog.Seconds
+		taskBoards[worklog.TaskID] = worklog.BoardID
+	}
+
+	boardIDs := make([]uint, 0, len(boardTotals))
+	for id := range boardTotals {
+		boardIDs = append(boardIDs, id)
+	}
+	var boards []models.Board
+	if err := db.Unscoped().Where("id IN ?", boardIDs).Find(&boards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch boards"})
+		return
+	}
+	boardTitles := make(map[uint]string, len(boards))
+	for _, board := range boards {
+		boardTitles[board.ID] = board.Title
+	}
+	taskTitles := make(map[uint]string, len(responses))
+	for _, response := range responses {
+		taskTitles[response.TaskID] = response.TaskTitle
+	}
+
+	timesheet := models.TimesheetResponse{
+		From:       from,
+		To:         to,
+		TotalHours: workedHours(total),
+		Days:       []models.TimesheetDay{},
+		Boards:     []models.TimesheetBoard{},
+		Tasks:      []models.TimesheetTask{},
+		Worklogs:   responses,
+	}
+	for date, seconds := range dayTotals {
+		timesheet.Days = append(timesheet.Days, models.TimesheetDay{Date: date, Hours: workedHours(seconds)})
+	}
+	for id, seconds := range boardTotals {
+		timesheet.Boards = append(timesheet.Boards, models.TimesheetBoard{BoardID: id, BoardTitle: boardTitles[id], Hours: workedHours(seconds)})
+	}
+	for id, seconds := range taskTotals {
+		timesheet.Tasks = append(timesheet.Tasks, models.TimesheetTask{TaskID: id, BoardID: taskBoards[id], TaskTitle: taskTitles[id], Hours: workedHours(seconds)})
+	}
+	sort.Slice(timesheet.Days, func(i, j int) bool { return timesheet.Days[i].Date < timesheet.Days[j].Date })
+	sort.Slice(timesheet.Boards, func(i, j int) bool {
+		if timesheet.Boards[i].Hours != timesheet.Boards[j].Hours {
+			return timesheet.Boards[i].Hours > timesheet.Boards[j].Hours
+		}
+		return timesheet.Boards[i].BoardID < timesheet.Boards[j].BoardID
+	})
+	sort.Slice(timesheet.Tasks, func(i, j int) bool {
+		if timesheet.Tasks[i].Hours != timesheet.Tasks[j].Hours {
+			return timesheet.Tasks[i].Hours > timesheet.Tasks[j].Hours
+		}
+		return timesheet.Tasks[i].TaskID < timesheet.Tasks[j].TaskID
+	})
+
+	c.JSON(http.StatusOK, timesheet)
+}
+
+// broadcastWorklog announces a worklog change with the task's new actual
+// hours and returns the worklog's response.
+func (h *WorklogHandler) broadcastWorklog(task *models.Task, event string, worklog *models.Worklog) models.WorklogResponse {
+	response := models.WorklogResponse{Worklog: *worklog, TaskTitle: task.Title}
+	if responses, err := worklogResponses(database.GetDB(), []models.Worklog{*worklog}); err == nil {
+		response = responses[0]
+	}
+
+	h.hub.BroadcastToBoard(task.BoardID, event, gin.H{
+		"worklog":      response,
+		"actual_hours": task.ActualHours,
+	})
+	return response
+}
+
+// loadWorklogTask loads the :id task and checks that the caller holds action
+// on its board, or only board access when action is empty. It writes the
+// error response and returns ok false on failure.
+func loadWorklogTask(c *gin.Context, action string) (*models.Task, bool) {
+	taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return nil, false
+	}
+
+	var task models.Task
+	if err := database.GetDB().First(&task, taskID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if action == "" {
+		if !hasAccessToBoard(task.BoardID, userID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return nil, false
+		}
+	} else if !hasPermissionOnBoard(task.BoardID, userID, action) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return nil, false
+	}
+	return &task, true
+}
+
+// loadTaskWorklog loads the :worklogId worklog of the :id task for a change by
+// the caller, who must own it or hold manage_board. It writes the error
+// response and returns ok false on failure.
+func loadTaskWorklog(c *gin.Context) (*models.Task, *models.Worklog, bool) {
+	task, ok := loadWorklogTask(c, "edit_task")
+	if !ok {
+		return nil, nil, false
+	}
+	worklogID, err := strconv.ParseUint(c.Param("worklogId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worklog ID"})
+		return nil, nil, false
+	}
+
+	var worklog models.Worklog
+	if err := database.GetDB().Where("id = ? AND task_id = ?", worklogID, task.ID).First(&worklog).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Worklog not found"})
+		return nil, nil, false
+	}
+
+	userID := middleware.GetUserID(c)
+	if worklog.UserID != userID && !hasPermissionOnBoard(task.BoardID, userID, "manage_board") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only change your own worklogs"})
+		return nil, nil, false
+	}
+	return task, &worklog, true
+}
+
+// worklogEnd works out when a logged period ends from ended_at or
+// duration_minutes, writing a 400 response if the period is invalid.
+func worklogEnd(c *gin.Context, req *models.WorklogRequest) (time.Time, bool) {
+	var endedAt time.Time
+	switch {
+	case req.EndedAt != nil:
+		endedAt = *req.EndedAt
+	case req.DurationMinutes != nil:
+		if *req.DurationMinutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "duration_minutes must be positive"})
+			return time.Time{}, false
+		}
+		endedAt = req.StartedAt.Add(time.Duration(*req.DurationMinutes * float64(time.Minute)))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ended_at or duration_minutes is required"})
+		return time.Time{}, false
+	}
+
+	if !endedAt.After(req.StartedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ended_at must be after started_at"})
+		return time.Time{}, false
+	}
+	// Allow for some clock skew between client and server
+	if endedAt.After(time.Now().Add(time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Worklogs cannot end in the future"})
+		return time.Time{}, false
+	}
+	return endedAt, true
+}
+
+// runningTimer returns the user's open worklog on a task, or nil if no timer
+// is running.
+func runningTimer(db *gorm.DB, taskID, userID uint) (*models.Worklog, error) {
+	var worklog models.Worklog
+	err := db.Where("task_id = ? AND user_id = ? AND ended_at IS NULL", taskID, userID).First(&worklog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &worklog, nil
+}
+
+// syncActualHours sets a task's actual hours to the sum of its finished
+// worklogs, or clears them when there are none, and audits the change.
+func syncActualHours(tx *gorm.DB, task *models.Task, actorID uint) error {
+	hours, err := finishedWorklogHours(tx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	before := taskFields(task, nil, nil)
+	task.ActualHours = hours
+	changes := diffTaskFields(before, taskFields(task, nil, nil))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := tx.Model(task).Update("actual_hours", hours).Error; err != nil {
+		return err
+	}
+	return recordTaskActivity(tx, task, actorID, models.TaskActionUpdated, models.ActivitySourceREST, changes)
+}
+
+// finishedWorklogHours sums the finished worklogs of a task, or returns nil
+// when it has none.
+func finishedWorklogHours(tx *gorm.DB, taskID uint) (*float64, error) {
+	var total struct {
+		Count   int64
+		Seconds int64
+	}
+	if err := tx.Model(&models.Worklog{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(seconds),0) AS seconds").
+		Where("task_id = ? AND ended_at IS NOT NULL", taskID).
+		Scan(&total).Error; err != nil {
+		return nil, err
+	}
+
+	if total.Count == 0 {
+		return nil, nil
+	}
+	hours := workedHours(total.Seconds)
+	return &hours, nil
+}
+
+// worklogResponses adds user names, task titles and hours to worklogs. Running
+// timers count the time elapsed so far.
+func worklogResponses(db *gorm.DB, worklogs []models.Worklog) ([]models.WorklogResponse, error) {
+	responses := make([]models.WorklogResponse, 0, len(worklogs))
+	if len(worklogs) == 0 {
+		return responses, nil
+	}
+
+	userIDs := make([]uint, 0, len(worklogs))
+	taskIDs := make([]uint, 0, len(worklogs))
+	for _, worklog := range worklogs {
+		userIDs = append(userIDs, worklog.UserID)
+		taskIDs = append(taskIDs, worklog.TaskID)
+	}
+
+	var users []models.User
+	if err := db.Where("id IN ?", uniqueUints(userIDs)).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	userNames := make(map[uint]string, len(users))
+	for _, user := range users {
+		userNames[user.ID] = user.Name
+	}
+
+	// Worklogs of trashed tasks still show up on timesheets
+	var tasks []models.Task
+	if err := db.Unscoped().Select("id, title").Where("id IN ?", uniqueUints(taskIDs)).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	taskTitles := make(map[uint]string, len(tasks))
+	for _, task := range tasks {
+		taskTitles[task.ID] = task.Title
+	}
+
+	now := time.Now()
+	for _, worklog := range worklogs {
+		response := models.WorklogResponse{
+			Worklog:   worklog,
+			UserName:  userNames[worklog.UserID],
+			TaskTitle: taskTitles[worklog.TaskID],
+			Hours:     workedHours(worklog.Seconds),
+			Running:   worklog.EndedAt == nil,
+		}
+		if response.Running {
+			response.Hours = workedHours(int64(now.Sub(worklog.StartedAt).Seconds()))
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// workedHours converts seconds to hours rounded to two decimals.
+func workedHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/36) / 100
+}
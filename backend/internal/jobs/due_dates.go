@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/websocket"
+
+	"gorm.io/gorm"
+)
+
+// DueSoonWindow returns how far ahead of its due date a task is announced as
+// due soon. It is read from DUE_SOON_HOURS and defaults to 24 hours.
+func DueSoonWindow() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("DUE_SOON_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 24
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// DueDateScheduler broadcasts task_due_soon and task_overdue events on the
+// board hub. Each event is sent once per due date, and tasks in the board's
+// last column are considered finished and skipped.
+type DueDateScheduler struct {
+	db       *gorm.DB
+	hub      *websocket.Hub
+	window   time.Duration
+	interval time.Duration
+}
+
+func NewDueDateScheduler(db *gorm.DB, hub *websocket.Hub, window, interval time.Duration) *DueDateScheduler {
+	return &DueDateScheduler{db: db, hub: hub, window: window, interval: interval}
+}
+
+// Run checks due dates immediately and then on every interval. It never returns.
+func (s *DueDateScheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.CheckDueDates(time.Now()); err != nil {
+			logger.Log.Errorf("Failed to check due dates: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// unfinishedTask matches tasks outside their board's last column. Boards
+// without columns fall back to the default template's done status.
+const unfinishedTask = `tasks.status <> COALESCE((SELECT columns.status FROM columns WHERE columns.board_id = tasks.board_id AND columns.deleted_at IS NULL ORDER BY columns.position DESC LIMIT 1), ?)`
+
+// CheckDueDates sends the events that have become due as of now.
+func (s *DueDateScheduler) CheckDueDates(now time.Time) error {
+	defaults := models.BuiltinBoardTemplates[0].Columns
+	defaultDone := defaults[len(defaults)-1].Status
+
+	var overdue []models.Task
+	if err := s.liveBoardTasks().Where("tasks.due_date < ? AND tasks.overdue_notified_at IS NULL", now).Where(unfinishedTask, defaultDone).Find(&overdue).Error; err != nil {
+		return err
+	}
+	if err := s.notify(overdue, "task_overdue", "overdue_notified_at", now); err != nil {
+		return err
+	}
+
+	var dueSoon []models.Task
+	if err := s.liveBoardTasks().Where("tasks.due_date >= ? AND tasks.due_date <= ? AND tasks.due_soon_notified_at IS NULL", now, now.Add(s.window)).Where(unfinishedTask, defaultDone).Find(&dueSoon).Error; err != nil {
+		return err
+	}
+	return s.notify(dueSoon, "task_due_soon", "due_soon_notified_at", now)
+}
+
+// liveBoardTasks queries tasks whose board is not in the trash.
+func (s *DueDateScheduler) liveBoardTasks() *gorm.DB {
+	return s.db.Model(&models.Task{}).Joins("JOIN boards ON boards.id = tasks.board_id AND boards.deleted_at IS NULL")
+}
+
+// notify broadcasts event for every task and stamps the marker column so the
+// event is not repeated.
+func (s *DueDateScheduler) notify(tasks []models.Task, event, marker string, now time.Time) error {
+	for _, task := range tasks {
+		if err := s.db.Model(&models.Task{}).Where("id = ?", task.ID).UpdateColumn(marker, now).Error; err != nil {
+			return err
+		}
+
+		assigneeIDs := []uint{}
+		if err := s.db.Model(&models.TaskAssignee{}).Where("task_id = ?", task.ID).Order("user_id asc").Pluck("user_id", &assigneeIDs).Error; err != nil {
+			return err
+		}
+
+		s.hub.BroadcastToBoard(task.BoardID, event, map[string]interface{}{
+			"task_id":      task.ID,
+			"title":        task.Title,
+			"status":       task.Status,
+			"assignee_id":  task.AssigneeID,
+			"assignee_ids": assigneeIDs,
+			"due_date":     task.DueDate,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"errors"
+	"time"
+
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/models"
+	"kanban-backend/internal/recurrence"
+	"kanban-backend/internal/websocket"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// taskPositionGap spaces created tasks like the task handlers do.
+const taskPositionGap = 1024.0
+
+// TaskResponseLoader builds the API representation of a task, so created
+// tasks are broadcast exactly like tasks created through the API.
+type TaskResponseLoader func(taskID uint) (models.TaskResponse, error)
+
+// RecurringTaskScheduler clones recurring tasks into real tasks as their
+// occurrences come due and announces them with task_created. Each occurrence
+// is recorded in the same transaction as its task, so restarts never create
+// it twice. WIP limits are not enforced for scheduled tasks.
+type RecurringTaskScheduler struct {
+	db       *gorm.DB
+	hub      *websocket.Hub
+	loadTask TaskResponseLoader
+	interval time.Duration
+}
+
+func NewRecurringTaskScheduler(db *gorm.DB, hub *websocket.Hub, loadTask TaskResponseLoader, interval time.Duration) *RecurringTaskScheduler {
+	return &RecurringTaskScheduler{db: db, hub: hub, loadTask: loadTask, interval: interval}
+}
+
+// Run creates due tasks immediately and then on every interval. It never returns.
+func (s *RecurringTaskScheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.CreateDueTasks(time.Now()); err != nil {
+			logger.Log.Errorf("Failed to create recurring tasks: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// CreateDueTasks creates a task for every recurring task whose next run has
+// passed as of now. Occurrences missed while the server was down collapse
+// into a single task for the latest one.
+func (s *RecurringTaskScheduler) CreateDueTasks(now time.Time) error {
+	var due []models.RecurringTask
+	if err := s.db.Joins("JOIN boards ON boards.id = recurring_tasks.board_id AND boards.deleted_at IS NULL").
+		Where("recurring_tasks.paused = ? AND recurring_tasks.next_run_at <= ?", false, now.UTC()).
+		Find(&due).Error; err != nil {
+		return err
+	}
+
+	for i := range due {
+		taskID, err := s.runOccurrence(&due[i], now)
+		if err != nil {
+			logger.Log.Errorf("Failed to create task for recurring task %d: %v", due[i].ID, err)
+			continue
+		}
+		if taskID == 0 {
+			continue
+		}
+
+		response, err := s.loadTask(taskID)
+		if err != nil {
+			logger.Log.Errorf("Failed to load recurring task instance %d: %v", taskID, err)
+			continue
+		}
+		s.hub.BroadcastToBoard(due[i].BoardID, "task_created", response)
+	}
+	return nil
+}
+
+// runOccurrence creates the task for the latest due occurrence of rt and
+// schedules the next run. It returns the new task's ID, or 0 when the
+// occurrence already had a task.
+func (s *RecurringTaskScheduler) runOccurrence(rt *models.RecurringTask, now time.Time) (uint, error) {
+	rule, err := recurrence.New(rt.Frequency, rt.Interval, rt.ByDay, rt.ByMonthDay, rt.StartsAt, rt.Until, rt.Timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	occurrence := *rt.NextRunAt
+	for {
+		next, ok := rule.Next(occurrence)
+		if !ok || next.After(now) {
+			break
+		}
+		occurrence = next
+	}
+
+	var nextRun *time.Time
+	if next, ok := rule.Next(occurrence); ok {
+		next = next.UTC()
+		nextRun = &next
+	}
+
+	var taskID uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		run := models.RecurringTaskRun{RecurringTaskID: rt.ID, OccurrenceAt: occurrence.UTC()}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&run)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected > 0 {
+			task, err := createRecurringTaskInstance(tx, rt, occurrence)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&run).Update("task_id", task.ID).Error; err != nil {
+				return err
+			}
+			taskID = task.ID
+			rt.LastTaskID = &task.ID
+		}
+
+		return tx.Model(rt).Updates(map[string]interface{}{
+			"next_run_at":  nextRun,
+			"last_run_at":  now,
+			"last_task_id": rt.LastTaskID,
+		}).Error
+	})
+	return taskID, err
+}
+
+// createRecurringTaskInstance clones rt into a task at the end of its column.
+// Assignees who left the board and labels that were deleted are dropped.
+func createRecurringTaskInstance(tx *gorm.DB, rt *models.RecurringTask, occurrence time.Time) (*models.Task, error) {
+	status, err := recurringTaskStatus(tx, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	var maxPosition float64
+	if err := tx.Model(&models.Task{}).
+		Where("board_id = ? AND status = ?", rt.BoardID, status).
+		Select("COALESCE(MAX(position),0)").
+		Scan(&maxPosition).Error; err != nil {
+		return nil, err
+	}
+
+	startDate := occurrence.UTC()
+	task := models.Task{
+		Title:          rt.Title,
+		Description:    rt.Description,
+		Priority:       rt.Priority,
+		Category:       rt.Category,
+		Status:         status,
+		Position:       maxPosition + taskPositionGap,
+		BoardID:        rt.BoardID,
+		CreatedBy:      rt.CreatedBy,
+		EstimatedHours: rt.EstimatedHours,
+		StartDate:      &startDate,
+	}
+	if rt.DueInDays != nil {
+		dueDate := occurrence.AddDate(0, 0, *rt.DueInDays).UTC()
+		task.DueDate = &dueDate
+	}
+	if err := tx.Create(&task).Error; err != nil {
+		return nil, err
+	}
+
+	assigneeIDs := []uint{}
+	if len(rt.AssigneeIDs) > 0 {
+		var memberIDs []uint
+		if err := tx.Model(&models.BoardMember{}).Where("board_id = ? AND user_id IN ?", rt.BoardID, rt.AssigneeIDs).Pluck("user_id", &memberIDs).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range rt.AssigneeIDs {
+			if containsID(memberIDs, id) && !containsID(assigneeIDs, id) {
+				assigneeIDs = append(assigneeIDs, id)
+			}
+		}
+	}
+	for _, id := range assigneeIDs {
+		if err := tx.Create(&models.TaskAssignee{TaskID: task.ID, UserID: id}).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(assigneeIDs) > 0 {
+		task.AssigneeID = &assigneeIDs[0]
+		if err := tx.Model(&task).Update("assignee_id", task.AssigneeID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	labelNames := []string{}
+	if len(rt.LabelIDs) > 0 {
+		var labels []models.Label
+		if err := tx.Where("id IN ? AND board_id = ?", rt.LabelIDs, rt.BoardID).Order("name asc").Find(&labels).Error; err != nil {
+			return nil, err
+		}
+		for _, label := range labels {
+			if err := tx.Create(&models.TaskLabel{TaskID: task.ID, LabelID: label.ID}).Error; err != nil {
+				return nil, err
+			}
+			labelNames = append(labelNames, label.Name)
+		}
+	}
+
+	changes := []models.FieldChange{
+		{Field: "title", New: task.Title},
+		{Field: "priority", New: task.Priority},
+		{Field: "status", New: task.Status},
+	}
+	if len(assigneeIDs) > 0 {
+		changes = append(changes, models.FieldChange{Field: "assignee_ids", New: assigneeIDs})
+	}
+	if len(labelNames) > 0 {
+		changes = append(changes, models.FieldChange{Field: "labels", New: labelNames})
+	}
+	activity := models.TaskActivity{
+		TaskID:  task.ID,
+		BoardID: task.BoardID,
+		Action:  models.TaskActionCreated,
+		Source:  models.ActivitySourceScheduler,
+		Changes: changes,
+	}
+	if err := tx.Create(&activity).Error; err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// recurringTaskStatus returns the column a recurring task is created in. If
+// its column was removed the board's first column is used instead.
+func recurringTaskStatus(tx *gorm.DB, rt *models.RecurringTask) (string, error) {
+	var count int64
+	if err := tx.Model(&models.Column{}).Where("board_id = ? AND status = ?", rt.BoardID, rt.Status).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return rt.Status, nil
+	}
+
+	var first models.Column
+	err := tx.Where("board_id = ?", rt.BoardID).Order("position asc").First(&first).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return rt.Status, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return first.Status, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TrashRetention returns how long soft-deleted boards, columns and tasks stay
+// restorable. It is read from TRASH_RETENTION_DAYS and defaults to 30 days.
+func TrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// TrashPurger permanently deletes trashed entities once their retention
+// window has passed.
+type TrashPurger struct {
+	db        *gorm.DB
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(db *gorm.DB, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{db: db, retention: retention, interval: interval}
+}
+
+// Run purges once immediately and then on every interval. It never returns.
+func (p *TrashPurger) Run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := PurgeTrash(p.db, time.Now().Add(-p.retention)); err != nil {
+			logger.Log.Errorf("Failed to purge trash: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// PurgeTrash hard-deletes boards, columns and tasks that were trashed before
+// cutoff. Boards take every row that depends on them along.
+func PurgeTrash(db *gorm.DB, cutoff time.Time) error {
+	var boardIDs []uint
+	if err := db.Unscoped().Model(&models.Board{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Pluck("id", &boardIDs).Error; err != nil {
+		return err
+	}
+	for _, boardID := range boardIDs {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeBoard(tx, boardID) }); err != nil {
+			return err
+		}
+	}
+
+	var taskIDs []uint
+	if err := db.Unscoped().Model(&models.Task{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Pluck("id", &taskIDs).Error; err != nil {
+		return err
+	}
+	if len(taskIDs) > 0 {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskLabel{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskAssignee{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskWatcher{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskCustomFieldValue{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.Worklog{}).Error; err != nil {
+				return err
+			}
+			commentIDs := tx.Model(&models.TaskComment{}).Select("id").Where("task_id IN ?", taskIDs)
+			if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.TaskCommentRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.TaskComment{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN ?", taskIDs).Delete(&models.ChecklistItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("source_id IN ? OR target_id IN ?", taskIDs, taskIDs).Delete(&models.TaskLink{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Model(&models.Task{}).Where("parent_id IN ?", taskIDs).Update("parent_id", nil).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN ?", taskIDs).Delete(&models.Task{}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&models.Column{}).Error; err != nil {
+		return err
+	}
+
+	if len(boardIDs) > 0 || len(taskIDs) > 0 {
+		logger.Log.Infof("Purged %d boards and %d tasks from trash", len(boardIDs), len(taskIDs))
+	}
+	return nil
+}
+
+// purgeBoard deletes a board and everything scoped to it.
+func purgeBoard(tx *gorm.DB, boardID uint) error {
+	taskIDs := tx.Unscoped().Model(&models.Task{}).Select("id").Where("board_id = ?", boardID)
+	memberIDs := tx.Model(&models.BoardMember{}).Select("id").Where("board_id = ?", boardID)
+	commentIDs := tx.Model(&models.TaskComment{}).Select("id").Where("board_id = ?", boardID)
+	recurringTaskIDs := tx.Model(&models.RecurringTask{}).Select("id").Where("board_id = ?", boardID)
+
+	steps := []*gorm.DB{
+		tx.Where("task_id IN (?)", taskIDs).Delete(&models.TaskLabel{}),
+		tx.Where("task_id IN (?)", taskIDs).Delete(&models.TaskAssignee{}),
+		tx.Where("task_id IN (?)", taskIDs).Delete(&models.TaskWatcher{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.Label{}),
+		tx.Where("task_id IN (?)", taskIDs).Delete(&models.TaskCustomFieldValue{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.CustomFieldDefinition{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.Worklog{}),
+		tx.Where("recurring_task_id IN (?)", recurringTaskIDs).Delete(&models.RecurringTaskRun{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.RecurringTask{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.AutomationRun{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.AutomationRule{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.WebhookDelivery{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.Webhook{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.TaskActivity{}),
+		tx.Where("comment_id IN (?)", commentIDs).Delete(&models.TaskCommentRevision{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.TaskComment{}),
+		tx.Where("task_id IN (?)", taskIDs).Delete(&models.ChecklistItem{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.TaskLink{}),
+		tx.Unscoped().Where("board_id = ?", boardID).Delete(&models.Task{}),
+		tx.Unscoped().Where("board_id = ?", boardID).Delete(&models.Column{}),
+		tx.Where("member_id IN (?)", memberIDs).Delete(&models.MemberPermission{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.BoardMember{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.BoardSettings{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.Invitation{}),
+		tx.Where("board_id = ?", boardID).Delete(&models.ChatMessage{}),
+		tx.Unscoped().Delete(&models.Board{}, boardID),
+	}
+	for _, step := range steps {
+		if step.Error != nil {
+			return step.Error
+		}
+	}
+	return nil
+}
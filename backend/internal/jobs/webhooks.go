@@ -0,0 +1,248 @@
+package jobs
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kanban-backend/internal/logger"
+	"kanban-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookMaxAttempts is how often a delivery is tried before it fails.
+	webhookMaxAttempts = 8
+
+	// webhookRetryBase is the wait after the first failed attempt. It doubles
+	// with every further attempt, so the last retry comes about an hour in.
+	webhookRetryBase = 30 * time.Second
+
+	// webhookTimeout bounds a single attempt.
+	webhookTimeout = 10 * time.Second
+
+	// webhookLease is how long a delivery being attempted is hidden from other
+	// dispatchers. It must exceed webhookTimeout.
+	webhookLease = time.Minute
+
+	// webhookBatchSize caps the deliveries attempted per tick.
+	webhookBatchSize = 50
+
+	// webhookDeliveryRetention is how long finished deliveries stay in the log.
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+
+	// webhookResponseLimit caps the response body kept in the log.
+	webhookResponseLimit = 2048
+)
+
+// WebhookDispatcher queues board events for the webhooks subscribed to them
+// and delivers the queue. Deliveries are stored before they are attempted, so
+// they survive restarts.
+type WebhookDispatcher struct {
+	db       *gorm.DB
+	client   *http.Client
+	interval time.Duration
+}
+
+func NewWebhookDispatcher(db *gorm.DB, interval time.Duration) *WebhookDispatcher {
+	return &WebhookDispatcher{db: db, client: &http.Client{Timeout: webhookTimeout}, interval: interval}
+}
+
+// Enqueue queues event for every enabled webhook of the board subscribed to
+// it. It is registered as a board event listener on the hub.
+func (d *WebhookDispatcher) Enqueue(boardID uint, event string, data interface{}) {
+	var webhooks []models.Webhook
+	if err := d.db.Where("board_id = ? AND enabled = ?", boardID, true).Find(&webhooks).Error; err != nil {
+		logger.Log.Errorf("Failed to load webhooks of board %d: %v", boardID, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !SubscribesTo(&webhook, event) {
+			continue
+		}
+		if _, err := QueueWebhookDelivery(d.db, &webhook, event, data, nil); err != nil {
+			logger.Log.Errorf("Failed to queue %s for webhook %d: %v", event, webhook.ID, err)
+		}
+	}
+}
+
+// SubscribesTo reports whether webhook receives event. Pings always go out.
+func SubscribesTo(webhook *models.Webhook, event string) bool {
+	if event == models.WebhookEventPing {
+		return true
+	}
+	for _, subscribed := range webhook.Events {
+		if subscribed == models.WebhookEventAll || subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueWebhookDelivery stores a pending delivery of event to webhook, due
+// immediately.
+func QueueWebhookDelivery(db *gorm.DB, webhook *models.Webhook, event string, data interface{}, redeliveryOf *uint) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(models.WebhookPayload{
+		Event:      event,
+		BoardID:    webhook.BoardID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queueWebhookPayload(db, webhook, event, string(payload), redeliveryOf)
+}
+
+// RequeueWebhookDelivery queues the payload of an earlier delivery again as a
+// new delivery.
+func RequeueWebhookDelivery(db *gorm.DB, webhook *models.Webhook, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	return queueWebhookPayload(db, webhook, delivery.Event, delivery.Payload, &delivery.ID)
+}
+
+func queueWebhookPayload(db *gorm.DB, webhook *models.Webhook, event, payload string, redeliveryOf *uint) (*models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		BoardID:       webhook.BoardID,
+		Event:         event,
+		Payload:       payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		RedeliveryOf:  redeliveryOf,
+	}
+	if err := db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// SignWebhookPayload returns the X-Webhook-Signature-256 header value for body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run delivers due webhooks immediately and then on every interval. It never
+// returns.
+func (d *WebhookDispatcher) Run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(time.Now()); err != nil {
+			logger.Log.Errorf("Failed to deliver webhooks: %v", err)
+		}
+		<-ticker.C
+	}
+}
+
+// DeliverDue attempts the pending deliveries that are due as of now and
+// prunes old finished ones.
+func (d *WebhookDispatcher) DeliverDue(now time.Time) error {
+	now = now.UTC()
+
+	var due []models.WebhookDelivery
+	if err := d.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("id asc").Limit(webhookBatchSize).
+		Find(&due).Error; err != nil {
+		return err
+	}
+
+	for i := range due {
+		if err := d.deliver(&due[i], now); err != nil {
+			logger.Log.Errorf("Failed to deliver webhook delivery %d: %v", due[i].ID, err)
+		}
+	}
+
+	return d.db.Where("status <> ? AND created_at < ?", models.WebhookDeliveryPending, now.Add(-webhookDeliveryRetention)).
+		Delete(&models.WebhookDelivery{}).Error
+}
+
+// deliver makes one attempt at delivery and records its outcome. The delivery
+// is leased first so concurrent dispatchers never send it twice at once.
+func (d *WebhookDispatcher) deliver(delivery *models.WebhookDelivery, now time.Time) error {
+	lease := now.Add(webhookLease)
+	result := d.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.WebhookDeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", lease)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	var webhook models.Webhook
+	if err := d.db.First(&webhook, delivery.WebhookID).Error; err != nil || !webhook.Enabled {
+		return d.db.Model(delivery).Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryFailed,
+			"next_attempt_at": nil,
+			"error":           "webhook was disabled or deleted",
+		}).Error
+	}
+
+	started := time.Now()
+	status, body, err := d.post(&webhook, delivery)
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"response_status": status,
+		"response_body":   body,
+		"duration_ms":     time.Since(started).Milliseconds(),
+		"error":           "",
+	}
+	if err == nil && (status < 200 || status > 299) {
+		err = fmt.Errorf("endpoint responded with status %d", status)
+	}
+
+	switch {
+	case err == nil:
+		delivered := time.Now().UTC()
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["next_attempt_at"] = nil
+		updates["delivered_at"] = &delivered
+	case delivery.Attempts+1 >= webhookMaxAttempts:
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["next_attempt_at"] = nil
+		updates["error"] = err.Error()
+	default:
+		retry := time.Now().UTC().Add(webhookRetryBase << delivery.Attempts)
+		updates["next_attempt_at"] = &retry
+		updates["error"] = err.Error()
+	}
+	return d.db.Model(delivery).Updates(updates).Error
+}
+
+// post sends a delivery and returns the response status and the start of its
+// body.
+func (d *WebhookDispatcher) post(webhook *models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kanban-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Webhook-Signature-256", SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	return resp.StatusCode, string(responseBody), nil
+}
@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"kanban-backend/internal/logger"
+)
+
+// LogMailer logs messages instead of sending them, for development and tests.
+// With a directory it also writes each message there as an .eml file.
+type LogMailer struct {
+	dir   string
+	count uint64
+}
+
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{dir: dir}
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	logger.Log.Infof("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405.000000000"), atomic.AddUint64(&m.count, 1))
+	return os.WriteFile(filepath.Join(m.dir, name), render(From(), msg), 0o644)
+}
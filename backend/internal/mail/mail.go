@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"os"
+	"strings"
+
+	"kanban-backend/internal/logger"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailerFromEnv returns the mailer MAIL_DRIVER names: "smtp" sends through
+// SMTP_HOST, anything else writes messages to the log, and to MAIL_DIR when
+// set.
+func NewMailerFromEnv() Mailer {
+	if strings.EqualFold(os.Getenv("MAIL_DRIVER"), "smtp") {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		if os.Getenv("SMTP_HOST") == "" {
+			logger.Log.Warn("MAIL_DRIVER is smtp but SMTP_HOST is not set; email will fail to send")
+		}
+		return NewSMTPMailer(os.Getenv("SMTP_HOST"), port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), From())
+	}
+	return NewLogMailer(os.Getenv("MAIL_DIR"))
+}
+
+// From returns the sender address from MAIL_FROM.
+func From() string {
+	if from := os.Getenv("MAIL_FROM"); from != "" {
+		return from
+	}
+	return "TaskflowAI <no-reply@localhost>"
+}
+
+// AppURL returns the frontend address links in emails point to, from APP_URL.
+func AppURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return "http://localhost:5173"
+}
@@ -3,8 +3,11 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"kanban-backend/internal/auth"
+	"kanban-backend/internal/database"
+	"kanban-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,6 +28,11 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if auth.IsPersonalAccessToken(tokenString) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
 		claims, err := auth.ValidateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
@@ -32,12 +40,64 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Tokens die with their session, so signing out or revoking a device
+		// takes effect before the token expires
+		var session models.Session
+		if claims.SessionID == 0 ||
+			database.GetDB().Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID).First(&session).Error != nil ||
+			!session.Active(time.Now()) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("session_id", claims.SessionID)
 		c.Next()
 	}
 }
 
+// personalAccessTokenTouchInterval limits how often a token's last use is
+// written back.
+const personalAccessTokenTouchInterval = time.Minute
+
+// authenticatePersonalAccessToken authenticates the request as the owner of a
+// personal access token, keeping its scopes and boards for RequireScope and
+// RequireBoard.
+func authenticatePersonalAccessToken(c *gin.Context, tokenString string) {
+	db := database.GetDB()
+	now := time.Now().UTC()
+
+	var token models.PersonalAccessToken
+	if err := db.Where("token_hash = ?", auth.HashPersonalAccessToken(tokenString)).First(&token).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+	if token.ExpiresAt != nil && !now.Before(*token.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+		c.Abort()
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, token.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= personalAccessTokenTouchInterval {
+		db.Model(&token).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": c.ClientIP()})
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user_email", user.Email)
+	c.Set("personal_access_token", &token)
+	c.Next()
+}
+
 func GetUserID(c *gin.Context) uint {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -52,4 +112,13 @@ func GetUserEmail(c *gin.Context) string {
 		return ""
 	}
 	return email.(string)
+}
+
+// GetSessionID returns the session of the request's access token.
+func GetSessionID(c *gin.Context) uint {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return 0
+	}
+	return sessionID.(uint)
 }
\ No newline at end of file
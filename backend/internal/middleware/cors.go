@@ -19,6 +19,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	config.AllowCredentials = true
 	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	config.ExposeHeaders = []string{"X-Next-Cursor"}
 
 	return cors.New(config)
 }
\ No newline at end of file
@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+// Automation triggers
+const (
+	AutomationTriggerTaskCreated = "task_created"
+	AutomationTriggerTaskUpdated = "task_updated"
+	AutomationTriggerTaskMoved   = "task_moved" // any status change, by move or update
+)
+
+// Automation action types
+const (
+	AutomationActionSetPriority     = "set_priority"
+	AutomationActionSetCategory     = "set_category"
+	AutomationActionMoveTo          = "move_to"
+	AutomationActionAssign          = "assign"
+	AutomationActionUnassign        = "unassign"
+	AutomationActionAddLabel        = "add_label"
+	AutomationActionRemoveLabel     = "remove_label"
+	AutomationActionFillActualHours = "fill_actual_hours"
+	AutomationActionNotify          = "notify"
+)
+
+// Automation run statuses
+const (
+	AutomationRunSucceeded     = "succeeded"
+	AutomationRunFailed        = "failed"
+	AutomationRunLoopPrevented = "loop_prevented"
+)
+
+// AutomationRule runs its actions on a task whenever Trigger fires for it and
+// every condition holds.
+type AutomationRule struct {
+	ID         uint                  `json:"id" gorm:"primaryKey"`
+	BoardID    uint                  `json:"board_id" gorm:"not null;index"`
+	Name       string                `json:"name" gorm:"not null"`
+	Enabled    bool                  `json:"enabled" gorm:"not null"`
+	Trigger    string                `json:"trigger" gorm:"not null"`
+	Conditions []AutomationCondition `json:"conditions" gorm:"serializer:json"`
+	Actions    []AutomationAction    `json:"actions" gorm:"serializer:json"`
+	Position   int                   `json:"position" gorm:"not null;default:0"` // rules run in this order
+	CreatedBy  uint                  `json:"created_by" gorm:"not null"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// AutomationCondition tests one field of the task. Fields are status,
+// priority, category, title, label_id and assignee_id.
+type AutomationCondition struct {
+	Field    string `json:"field" binding:"required"`
+	Operator string `json:"operator" binding:"required"`
+	Value    string `json:"value"`
+}
+
+// AutomationAction is one step of a rule. Value holds the priority, category,
+// status or message; UserIDs, LabelID and Target are used by the actions that
+// need them.
+type AutomationAction struct {
+	Type    string `json:"type" binding:"required"`
+	Value   string `json:"value,omitempty"`
+	UserIDs []uint `json:"user_ids,omitempty"`
+	LabelID *uint  `json:"label_id,omitempty"`
+	Target  string `json:"target,omitempty"` // notify: owners, creator, assignees, watchers or users
+}
+
+// AutomationRun logs one execution of a rule.
+type AutomationRun struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	RuleID    uint          `json:"rule_id" gorm:"not null;index"`
+	BoardID   uint          `json:"board_id" gorm:"not null;index"`
+	TaskID    uint          `json:"task_id" gorm:"not null"`
+	Trigger   string        `json:"trigger" gorm:"not null"`
+	Depth     int           `json:"depth"` // 0 for user changes, higher for changes made by rules
+	Status    string        `json:"status" gorm:"not null"`
+	Changes   []FieldChange `json:"changes" gorm:"serializer:json"`
+	Error     string        `json:"error,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type CreateAutomationRuleRequest struct {
+	Name       string                `json:"name" binding:"required,min=1,max=100"`
+	Enabled    *bool                 `json:"enabled"` // defaults to true
+	Trigger    string                `json:"trigger" binding:"required,oneof=task_created task_updated task_moved"`
+	Conditions []AutomationCondition `json:"conditions" binding:"dive"`
+	Actions    []AutomationAction    `json:"actions" binding:"required,min=1,dive"`
+	Position   *int                  `json:"position"`
+}
+
+// UpdateAutomationRuleRequest changes a rule. Omitted fields are left
+// unchanged; conditions and actions are replaced as a whole.
+type UpdateAutomationRuleRequest struct {
+	Name       *string               `json:"name" binding:"omitempty,min=1,max=100"`
+	Enabled    *bool                 `json:"enabled"`
+	Trigger    *string               `json:"trigger" binding:"omitempty,oneof=task_created task_updated task_moved"`
+	Conditions []AutomationCondition `json:"conditions" binding:"omitempty,dive"`
+	Actions    []AutomationAction    `json:"actions" binding:"omitempty,min=1,dive"`
+	Position   *int                  `json:"position"`
+}
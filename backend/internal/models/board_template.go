@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// BoardTemplate describes the columns, labels and settings a new board starts
+// with. Built-in templates are seeded at startup; users can save their own
+// from an existing board.
+type BoardTemplate struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name" gorm:"not null"`
+	Description string           `json:"description"`
+	IsBuiltin   bool             `json:"is_builtin" gorm:"default:false;index"`
+	CreatedBy   *uint            `json:"created_by" gorm:"index"` // nil for built-in templates
+	Columns     []TemplateColumn `json:"columns" gorm:"serializer:json"`
+	Labels      []TemplateLabel  `json:"labels" gorm:"serializer:json"`
+	Settings    TemplateSettings `json:"settings" gorm:"serializer:json"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+type TemplateColumn struct {
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Color    string `json:"color"`
+	WIPLimit *int   `json:"wip_limit,omitempty"`
+}
+
+type TemplateLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TemplateSettings is the subset of BoardSettings a template carries. LLM
+// credentials are never copied into templates.
+type TemplateSettings struct {
+	AllowGuestAccess             bool   `json:"allow_guest_access"`
+	RequireApprovalForNewMembers bool   `json:"require_approval_for_new_members"`
+	DefaultMemberRole            string `json:"default_member_role"`
+}
+
+// BuiltinBoardTemplates are kept in sync with the database by name at startup.
+// The first entry is used when a board is created without a template, and its
+// statuses are accepted on boards that have no columns yet.
+var BuiltinBoardTemplates = []BoardTemplate{
+	{
+		Name:        "Basic Kanban",
+		Description: "To do, in progress and done",
+		Columns: []TemplateColumn{
+			{Title: "To Do", Status: "todo", Color: "#64748b"},
+			{Title: "In Progress", Status: "inprogress", Color: "#3b82f6"},
+			{Title: "Done", Status: "done", Color: "#22c55e"},
+		},
+	},
+	{
+		Name:        "Scrum",
+		Description: "Product backlog through sprint review",
+		Columns: []TemplateColumn{
+			{Title: "Backlog", Status: "backlog", Color: "#64748b"},
+			{Title: "Sprint Backlog", Status: "todo", Color: "#a855f7"},
+			{Title: "In Progress", Status: "inprogress", Color: "#3b82f6"},
+			{Title: "Review", Status: "review", Color: "#f59e0b"},
+			{Title: "Done", Status: "done", Color: "#22c55e"},
+		},
+		Labels: []TemplateLabel{
+			{Name: "story", Color: "#3b82f6"},
+			{Name: "bug", Color: "#ef4444"},
+			{Name: "chore", Color: "#64748b"},
+			{Name: "spike", Color: "#a855f7"},
+		},
+	},
+	{
+		Name:        "Bug Triage",
+		Description: "Incoming reports through verified fixes",
+		Columns: []TemplateColumn{
+			{Title: "New", Status: "new", Color: "#ef4444"},
+			{Title: "Triaged", Status: "triaged", Color: "#f59e0b"},
+			{Title: "In Progress", Status: "inprogress", Color: "#3b82f6"},
+			{Title: "Fixed", Status: "fixed", Color: "#14b8a6"},
+			{Title: "Verified", Status: "done", Color: "#22c55e"},
+		},
+		Labels: []TemplateLabel{
+			{Name: "bug", Color: "#ef4444"},
+			{Name: "regression", Color: "#f97316"},
+			{Name: "crash", Color: "#b91c1c"},
+			{Name: "needs-info", Color: "#64748b"},
+		},
+	},
+	{
+		Name:        "Content Pipeline",
+		Description: "Ideas through published content",
+		Columns: []TemplateColumn{
+			{Title: "Ideas", Status: "ideas", Color: "#a855f7"},
+			{Title: "Drafting", Status: "drafting", Color: "#3b82f6"},
+			{Title: "Editing", Status: "editing", Color: "#f59e0b"},
+			{Title: "Scheduled", Status: "scheduled", Color: "#14b8a6"},
+			{Title: "Published", Status: "done", Color: "#22c55e"},
+		},
+		Labels: []TemplateLabel{
+			{Name: "blog", Color: "#3b82f6"},
+			{Name: "video", Color: "#ef4444"},
+			{Name: "social", Color: "#22c55e"},
+		},
+	},
+}
+
+// SaveBoardTemplateRequest captures an existing board as a user template.
+type SaveBoardTemplateRequest struct {
+	Name        string `json:"name" binding:"required,min=1"`
+	Description string `json:"description"`
+}
@@ -1,24 +1,79 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
 
 // Column represents a Kanban column/list belonging to a board.
 type Column struct {
     ID        uint      `json:"id" gorm:"primaryKey"`
-    BoardID   uint      `json:"board_id" gorm:"not null;index"`
+    BoardID   uint      `json:"board_id" gorm:"not null;index;uniqueIndex:idx_column_board_status,where:deleted_at IS NULL"`
     Title     string    `json:"title" gorm:"not null"`
-    Status    string    `json:"status" gorm:"not null;uniqueIndex:idx_board_status"` // slugified unique per board
+    Status    string    `json:"status" gorm:"not null;uniqueIndex:idx_column_board_status,where:deleted_at IS NULL"` // slugified unique per board among live columns
     Color     string    `json:"color"`
     Position  int       `json:"position" gorm:"not null;default:0"`
+    WIPLimit  *int      `json:"wip_limit"` // nil means unlimited
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"` // in the trash until purged
 
     Board Board `json:"-" gorm:"foreignKey:BoardID"`
 }
 
 // CreateColumnRequest is the payload for creating a new column.
 type CreateColumnRequest struct {
-    Title  string `json:"title" binding:"required,min=1"`
-    Status string `json:"status" binding:"required,min=1"`
-    Color  string `json:"color"`
+    Title    string `json:"title" binding:"required,min=1"`
+    Status   string `json:"status" binding:"required,min=1"`
+    Color    string `json:"color"`
+    WIPLimit *int   `json:"wip_limit" binding:"omitempty,min=1"`
+}
+
+// UpdateColumnRequest is the payload for renaming or recoloring a column and
+// changing its WIP limit. Omitted fields are left unchanged; a wip_limit of 0
+// removes the limit.
+type UpdateColumnRequest struct {
+    Title    *string `json:"title" binding:"omitempty,min=1"`
+    Color    *string `json:"color"`
+    WIPLimit *int    `json:"wip_limit" binding:"omitempty,min=0"`
+}
+
+// ReorderColumnsRequest lists every column of a board in its new order.
+type ReorderColumnsRequest struct {
+    ColumnIDs []uint `json:"column_ids" binding:"required,min=1"`
+}
+
+// OrphanedStatus is a task status on a board that no column renders.
+type OrphanedStatus struct {
+    Status    string `json:"status"`
+    TaskCount int64  `json:"task_count"`
+}
+
+// RepairStatusesRequest maps orphaned task statuses onto existing column statuses.
+type RepairStatusesRequest struct {
+    Mappings map[string]string `json:"mappings" binding:"required,min=1"`
+}
+
+// WIPLimitError is the 409 body returned when a change would push a column
+// over its WIP limit on a board that enforces limits strictly.
+type WIPLimitError struct {
+    Error     string `json:"error"`
+    Code      string `json:"code"`
+    ColumnID  uint   `json:"column_id"`
+    Status    string `json:"status"`
+    WIPLimit  int    `json:"wip_limit"`
+    TaskCount int64  `json:"task_count"` // tasks in the column before the change
+    Adding    int    `json:"adding"`
+}
+
+// TrashItem is a soft-deleted board, column or task awaiting purge.
+type TrashItem struct {
+    Type      string    `json:"type"` // board, column, task
+    ID        uint      `json:"id"`
+    BoardID   uint      `json:"board_id"`
+    Title     string    `json:"title"`
+    Status    string    `json:"status,omitempty"`
+    DeletedAt time.Time `json:"deleted_at"`
+    PurgeAt   time.Time `json:"purge_at"`
 }
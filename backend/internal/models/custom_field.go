@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Custom field types
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldSelect = "select"
+	CustomFieldDate   = "date"
+	CustomFieldUser   = "user"
+)
+
+// CustomFieldDefinition is a typed field every task on a board can carry, such
+// as story points or the affected environment.
+type CustomFieldDefinition struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BoardID   uint      `json:"board_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"not null"`
+	Type      string    `json:"type" gorm:"not null"`
+	Options   []string  `json:"options" gorm:"serializer:json"` // choices of a select field
+	Required  bool      `json:"required" gorm:"default:false"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskCustomFieldValue holds a task's value for one field in the column that
+// matches the field's type, so values can be filtered in SQL. Text and select
+// values use TextValue.
+type TaskCustomFieldValue struct {
+	TaskID      uint       `json:"task_id" gorm:"primaryKey"`
+	FieldID     uint       `json:"field_id" gorm:"primaryKey;index"`
+	TextValue   *string    `json:"text_value"`
+	NumberValue *float64   `json:"number_value"`
+	DateValue   *time.Time `json:"date_value"`
+	UserValue   *uint      `json:"user_value"`
+}
+
+type CreateCustomFieldRequest struct {
+	Name     string   `json:"name" binding:"required,min=1,max=50"`
+	Type     string   `json:"type" binding:"required,oneof=text number select date user"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+// UpdateCustomFieldRequest changes a field definition. Omitted fields are left
+// unchanged; the type of a field cannot change.
+type UpdateCustomFieldRequest struct {
+	Name     *string  `json:"name" binding:"omitempty,min=1,max=50"`
+	Options  []string `json:"options"`
+	Required *bool    `json:"required"`
+	Position *int     `json:"position"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Label is a board-scoped tag that tasks reference by ID. Names are unique per
+// board regardless of case.
+type Label struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	BoardID     uint      `json:"board_id" gorm:"not null;uniqueIndex:idx_labels_board_name"`
+	Name        string    `json:"name" gorm:"not null;uniqueIndex:idx_labels_board_name"`
+	Color       string    `json:"color" gorm:"not null;default:'#64748b'"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TaskLabel attaches a label to a task.
+type TaskLabel struct {
+	TaskID  uint `json:"task_id" gorm:"primaryKey"`
+	LabelID uint `json:"label_id" gorm:"primaryKey;index"`
+}
+
+// DefaultLabelColor is used for labels created without a color.
+const DefaultLabelColor = "#64748b"
+
+type LabelResponse struct {
+	Label
+	TaskCount int64 `json:"task_count"`
+}
+
+type CreateLabelRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=50"`
+	Color       string `json:"color" binding:"omitempty,hexcolor"`
+	Description string `json:"description"`
+}
+
+// UpdateLabelRequest changes a label. Omitted fields are left unchanged.
+type UpdateLabelRequest struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=50"`
+	Color       *string `json:"color" binding:"omitempty,hexcolor"`
+	Description *string `json:"description"`
+}
+
+// MergeLabelsRequest moves every task of the source labels to the target
+// label and deletes the sources.
+type MergeLabelsRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+	TargetID  uint   `json:"target_id" binding:"required"`
+}
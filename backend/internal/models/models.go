@@ -2,16 +2,19 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Email     string    `json:"email" gorm:"unique;not null"`
-	Name      string    `json:"name" gorm:"not null"`
-	Password  string    `json:"-" gorm:"not null"`
-	Avatar    string    `json:"avatar"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Email           string     `json:"email" gorm:"unique;not null"`
+	Name            string     `json:"name" gorm:"not null"`
+	Password        string     `json:"-" gorm:"not null"`
+	Avatar          string     `json:"avatar"`
+	EmailVerifiedAt *time.Time `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 
 	// Relationships
 	OwnedBoards []Board       `json:"owned_boards" gorm:"foreignKey:CreatedBy"`
@@ -26,6 +29,7 @@ type Board struct {
 	IsPublic    bool      `json:"is_public" gorm:"default:false"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"` // in the trash until purged
 
 	// Relationships
 	Creator     User          `json:"creator" gorm:"foreignKey:CreatedBy"`
@@ -83,6 +87,10 @@ type BoardSettings struct {
 	AllowGuestAccess             bool `json:"allow_guest_access" gorm:"default:false"`
 	RequireApprovalForNewMembers bool `json:"require_approval_for_new_members" gorm:"default:false"`
 	DefaultMemberRole            string `json:"default_member_role" gorm:"default:'member'"`
+	WIPEnforcement               string `json:"wip_enforcement" gorm:"not null;default:'hard'"` // hard, warn
+	BlockDoneWithOpenSubtasks    bool   `json:"block_done_with_open_subtasks" gorm:"default:false"`
+	BlockStartWithOpenBlockers   bool   `json:"block_start_with_open_blockers" gorm:"default:false"`
+	RequireTwoFactor             bool   `json:"require_two_factor" gorm:"default:false"` // members without 2FA lose access
 	
 	// LLM Configuration
 	LLMProvider  string `json:"llm_provider"`  // openai or openrouter
@@ -101,28 +109,28 @@ type Task struct {
 	Priority       string    `json:"priority" gorm:"not null;default:'medium'"` // low, medium, high
 	Category       string    `json:"category"`
 	Status         string    `json:"status" gorm:"not null;default:'todo'"` // todo, inprogress, done
+	Position       float64   `json:"position" gorm:"not null;default:0;index"` // rank within the status column
 	BoardID        uint      `json:"board_id" gorm:"not null"`
+	ParentID       *uint     `json:"parent_id" gorm:"index"` // set on subtasks
 	CreatedBy      uint      `json:"created_by" gorm:"not null"`
-	AssigneeID     *uint     `json:"assignee_id"`
+	AssigneeID     *uint     `json:"assignee_id"` // first of the task's assignees, see TaskAssignee
 	EstimatedHours *float64  `json:"estimated_hours"`
 	ActualHours    *float64  `json:"actual_hours"`
+	StartDate      *time.Time `json:"start_date"`
+	DueDate        *time.Time `json:"due_date" gorm:"index"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"` // in the trash until purged
+
+	// Set by the due date scheduler once the matching event was sent; cleared when the due date changes
+	DueSoonNotifiedAt *time.Time `json:"-"`
+	OverdueNotifiedAt *time.Time `json:"-"`
 
 	// Relationships
 	Board    Board     `json:"board" gorm:"foreignKey:BoardID"`
 	Creator  User      `json:"creator" gorm:"foreignKey:CreatedBy"`
 	Assignee *User     `json:"assignee" gorm:"foreignKey:AssigneeID"`
-	Tags     []TaskTag `json:"tags" gorm:"foreignKey:TaskID"`
-}
-
-type TaskTag struct {
-	ID     uint   `json:"id" gorm:"primaryKey"`
-	TaskID uint   `json:"task_id" gorm:"not null"`
-	Tag    string `json:"tag" gorm:"not null"`
-
-	// Relationships
-	Task Task `json:"task" gorm:"foreignKey:TaskID"`
+	Labels   []Label   `json:"labels" gorm:"many2many:task_labels"`
 }
 
 type Invitation struct {
@@ -188,11 +196,12 @@ type Appointment struct {
 
 // Response DTOs
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	Avatar    string    `json:"avatar"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Email         string    `json:"email"`
+	Name          string    `json:"name"`
+	Avatar        string    `json:"avatar"`
+	EmailVerified *bool     `json:"email_verified,omitempty"` // only shown to the user themselves
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type BoardResponse struct {
@@ -229,14 +238,25 @@ type TaskResponse struct {
 	Priority       string    `json:"priority"`
 	Category       string    `json:"category"`
 	Status         string    `json:"status"`
+	Position       float64   `json:"position"`
 	BoardID        uint      `json:"board_id"`
+	ParentID       *uint     `json:"parent_id"`
 	CreatedBy      uint      `json:"created_by"`
-	AssigneeID     *uint     `json:"assignee_id"`
+	AssigneeID     *uint     `json:"assignee_id"` // first of assignee_ids
+	AssigneeIDs    []uint    `json:"assignee_ids"`
+	WatcherIDs     []uint    `json:"watcher_ids"`
 	EstimatedHours *float64  `json:"estimated_hours"`
 	ActualHours    *float64  `json:"actual_hours"`
+	StartDate      *time.Time `json:"start_date"`
+	DueDate        *time.Time `json:"due_date"`
+	Overdue        bool      `json:"overdue"` // past due and not in the board's last column
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
-	Tags           []string  `json:"tags"`
+	Labels         []Label   `json:"labels"`
+	Tags           []string  `json:"tags"` // label names, kept for older clients
+	Progress       TaskProgress `json:"progress"`
+	CustomFields   map[uint]interface{} `json:"custom_fields"` // values by field ID
+	WIPExceeded    bool      `json:"wip_exceeded,omitempty"` // set in warn-only mode when the change overfilled the column
 }
 
 type AppointmentResponse struct {
@@ -262,6 +282,7 @@ type RegisterRequest struct {
 type CreateBoardRequest struct {
 	Title       string `json:"title" binding:"required,min=1"`
 	Description string `json:"description"`
+	TemplateID  *uint  `json:"template_id"` // defaults to the basic kanban template
 }
 
 type UpdateBoardRequest struct {
@@ -269,6 +290,18 @@ type UpdateBoardRequest struct {
 	Description string `json:"description"`
 }
 
+// UpdateBoardSettingsRequest changes board-wide settings. Omitted fields are
+// left unchanged.
+type UpdateBoardSettingsRequest struct {
+	AllowGuestAccess             *bool   `json:"allow_guest_access"`
+	RequireApprovalForNewMembers *bool   `json:"require_approval_for_new_members"`
+	DefaultMemberRole            *string `json:"default_member_role" binding:"omitempty,oneof=admin member viewer"`
+	WIPEnforcement               *string `json:"wip_enforcement" binding:"omitempty,oneof=hard warn"`
+	BlockDoneWithOpenSubtasks    *bool   `json:"block_done_with_open_subtasks"`
+	BlockStartWithOpenBlockers   *bool   `json:"block_start_with_open_blockers"`
+	RequireTwoFactor             *bool   `json:"require_two_factor"` // owners only
+}
+
 type InviteUserRequest struct {
 	Email string `json:"email" binding:"required,email"`
 	Role  string `json:"role" binding:"required,oneof=admin member viewer"`
@@ -280,9 +313,16 @@ type CreateTaskRequest struct {
 	Priority       string   `json:"priority" binding:"required,oneof=low medium high"`
 	Category       string   `json:"category"`
 	Status         string   `json:"status" binding:"required"`
-	AssigneeID     *uint    `json:"assignee_id"`
+	ParentID       *uint    `json:"parent_id"`
+	AssigneeID     *uint    `json:"assignee_id"` // used when assignee_ids is omitted
+	AssigneeIDs    []uint   `json:"assignee_ids"`
+	WatcherIDs     []uint   `json:"watcher_ids"`
 	EstimatedHours *float64 `json:"estimated_hours"`
-	Tags           []string `json:"tags"`
+	StartDate      *time.Time `json:"start_date"`
+	DueDate        *time.Time `json:"due_date"`
+	LabelIDs       []uint   `json:"label_ids"`
+	Tags           []string `json:"tags"` // label names; matched case-insensitively, unknown names create labels
+	CustomFields   map[uint]interface{} `json:"custom_fields"` // values by field ID; null clears a value
 }
 
 type UpdateTaskRequest struct {
@@ -291,10 +331,25 @@ type UpdateTaskRequest struct {
 	Priority       string   `json:"priority" binding:"required,oneof=low medium high"`
 	Category       string   `json:"category"`
 	Status         string   `json:"status" binding:"required"`
-	AssigneeID     *uint    `json:"assignee_id"`
+	AssigneeID     *uint    `json:"assignee_id"` // used when assignee_ids is omitted
+	AssigneeIDs    []uint   `json:"assignee_ids"`
+	WatcherIDs     []uint   `json:"watcher_ids"` // omit to leave watchers unchanged
 	EstimatedHours *float64 `json:"estimated_hours"`
-	ActualHours    *float64 `json:"actual_hours"`
-	Tags           []string `json:"tags"`
+	StartDate      *time.Time `json:"start_date"`
+	DueDate        *time.Time `json:"due_date"`
+	LabelIDs       []uint   `json:"label_ids"`
+	Tags           []string `json:"tags"` // label names; matched case-insensitively, unknown names create labels
+	CustomFields   map[uint]interface{} `json:"custom_fields"` // values by field ID; omitted fields keep their value, null clears one
+}
+
+// MoveTaskRequest moves a task to a column and places it between two
+// neighbours. The target column may be given by status or by column ID; when
+// neither neighbour is set the task is appended to the end of the column.
+type MoveTaskRequest struct {
+	Status   string `json:"status"`
+	ColumnID *uint  `json:"column_id"`
+	AfterID  *uint  `json:"after_id"`  // task that should directly precede the moved task
+	BeforeID *uint  `json:"before_id"` // task that should directly follow the moved task
 }
 
 type CreateAppointmentRequest struct {
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// UserIdentity links a user to their account at the OpenID Connect provider.
+type UserIdentity struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Issuer      string    `json:"issuer" gorm:"not null;uniqueIndex:idx_user_identity_subject"`
+	Subject     string    `json:"subject" gorm:"not null;uniqueIndex:idx_user_identity_subject"`
+	Email       string    `json:"email"` // as last reported by the provider
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OIDCLoginAttempt keeps a login's secrets between sending the browser to the
+// provider and its return. The state travels through the browser, so only
+// its hash is stored.
+type OIDCLoginAttempt struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"not null;uniqueIndex"`
+	Nonce        string    `json:"-" gorm:"not null"`
+	CodeVerifier string    `json:"-" gorm:"not null"`
+	RedirectPath string    `json:"redirect_path"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OIDCLoginAttempt) TableName() string {
+	return "oidc_login_attempts"
+}
+
+type SSOExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// One-time token purposes
+const (
+	OneTimeTokenEmailVerification = "email_verification"
+	OneTimeTokenPasswordReset     = "password_reset"
+	OneTimeTokenSSOLogin          = "sso_login" // handed to the frontend after an SSO login
+)
+
+// OneTimeToken is a single-use, time-limited token sent by email to verify an
+// address or reset a password, or handed over after an SSO login. Only its
+// hash is stored.
+type OneTimeToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
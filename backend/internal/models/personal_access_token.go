@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// PersonalAccessTokenScopes lists the scopes a personal access token can hold.
+// A write scope includes reading the same resource.
+var PersonalAccessTokenScopes = []string{
+	"boards:read", "boards:write",
+	"tasks:read", "tasks:write",
+	"chat:read", "chat:write",
+	"messages:read", "messages:write",
+	"appointments:read", "appointments:write",
+	"profile:read", "profile:write",
+}
+
+// PersonalAccessToken lets scripts and CI call the API as its user, limited to
+// its scopes and, when BoardIDs is set, to those boards. Only a hash of the
+// token is stored.
+type PersonalAccessToken struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenHash   string     `json:"-" gorm:"not null;uniqueIndex"`
+	TokenPrefix string     `json:"token_prefix"` // the first characters, to recognise the token by
+	Scopes      []string   `json:"scopes" gorm:"serializer:json"`
+	BoardIDs    []uint     `json:"board_ids" gorm:"serializer:json"` // empty for all of the user's boards
+	ExpiresAt   *time.Time `json:"expires_at"`                       // nil for never
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	BoardIDs  []uint     `json:"board_ids"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// PersonalAccessTokenSecretResponse is returned when a token is created, the
+// only time the token itself is shown.
+type PersonalAccessTokenSecretResponse struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// RecurringTask is a task template with a recurrence rule. The scheduler
+// clones it into a real task in its column whenever NextRunAt passes.
+type RecurringTask struct {
+	ID             uint     `json:"id" gorm:"primaryKey"`
+	BoardID        uint     `json:"board_id" gorm:"not null;index"`
+	CreatedBy      uint     `json:"created_by" gorm:"not null"`
+	Title          string   `json:"title" gorm:"not null"`
+	Description    string   `json:"description"`
+	Priority       string   `json:"priority" gorm:"default:'medium'"`
+	Category       string   `json:"category"`
+	Status         string   `json:"status" gorm:"not null"`
+	AssigneeIDs    []uint   `json:"assignee_ids" gorm:"serializer:json"`
+	LabelIDs       []uint   `json:"label_ids" gorm:"serializer:json"`
+	EstimatedHours *float64 `json:"estimated_hours"`
+	DueInDays      *int     `json:"due_in_days"` // due date of created tasks, counted from the occurrence
+
+	// Recurrence rule
+	Frequency  string     `json:"frequency" gorm:"not null"` // daily, weekly or monthly
+	Interval   int        `json:"interval" gorm:"not null;default:1"`
+	ByDay      []string   `json:"by_day" gorm:"serializer:json"`       // MO..SU, weekly rules only
+	ByMonthDay []int      `json:"by_month_day" gorm:"serializer:json"` // 1..31, monthly rules only
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null"`           // first occurrence and time of day
+	Until      *time.Time `json:"until"`
+	Timezone   string     `json:"timezone" gorm:"not null;default:'UTC'"`
+	Paused     bool       `json:"paused" gorm:"default:false"`
+
+	NextRunAt  *time.Time `json:"next_run_at" gorm:"index"` // nil once the rule has ended
+	LastRunAt  *time.Time `json:"last_run_at"`
+	LastTaskID *uint      `json:"last_task_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// RecurringTaskRun records the task created for one occurrence. Its unique
+// index keeps an occurrence from being created twice, even across restarts.
+type RecurringTaskRun struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	RecurringTaskID uint      `json:"recurring_task_id" gorm:"not null;uniqueIndex:idx_recurring_task_runs_occurrence"`
+	OccurrenceAt    time.Time `json:"occurrence_at" gorm:"not null;uniqueIndex:idx_recurring_task_runs_occurrence"`
+	TaskID          uint      `json:"task_id" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RecurringTaskRequest creates or replaces a recurring task.
+type RecurringTaskRequest struct {
+	Title          string     `json:"title" binding:"required,min=1"`
+	Description    string     `json:"description"`
+	Priority       string     `json:"priority" binding:"required,oneof=low medium high"`
+	Category       string     `json:"category"`
+	Status         string     `json:"status" binding:"required"`
+	AssigneeIDs    []uint     `json:"assignee_ids"`
+	LabelIDs       []uint     `json:"label_ids"`
+	EstimatedHours *float64   `json:"estimated_hours"`
+	DueInDays      *int       `json:"due_in_days" binding:"omitempty,min=0"`
+	Frequency      string     `json:"frequency" binding:"required,oneof=daily weekly monthly"`
+	Interval       int        `json:"interval"` // defaults to 1
+	ByDay          []string   `json:"by_day"`
+	ByMonthDay     []int      `json:"by_month_day"`
+	StartsAt       time.Time  `json:"starts_at" binding:"required"`
+	Until          *time.Time `json:"until"`
+	Timezone       string     `json:"timezone"` // IANA name, defaults to UTC
+	Paused         bool       `json:"paused"`
+}
+
+type RecurringTaskResponse struct {
+	RecurringTask
+	Upcoming []time.Time `json:"upcoming"` // the next few occurrences
+}
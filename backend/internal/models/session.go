@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Session is a signed-in device. Access tokens carry its ID and stop working
+// once it is revoked or expires. Only hashes of refresh tokens are stored.
+type Session struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	UserID            uint       `json:"user_id" gorm:"not null;index"`
+	RefreshTokenHash  string     `json:"-" gorm:"not null;uniqueIndex"`
+	PreviousTokenHash string     `json:"-" gorm:"index"` // the rotated-out token, kept to detect reuse
+	UserAgent         string     `json:"user_agent"`
+	IP                string     `json:"ip"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Active reports whether the session can still be used at now.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionResponse is a session as listed to its user.
+type SessionResponse struct {
+	Session
+	Current bool `json:"current"`
+}
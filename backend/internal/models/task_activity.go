@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Task activity actions
+const (
+	TaskActionCreated  = "created"
+	TaskActionUpdated  = "updated"
+	TaskActionMoved    = "moved"
+	TaskActionDeleted  = "deleted"
+	TaskActionRestored = "restored"
+)
+
+// Task activity sources
+const (
+	ActivitySourceREST       = "rest"
+	ActivitySourceLLM        = "llm"
+	ActivitySourceImport     = "import"
+	ActivitySourceScheduler  = "scheduler"
+	ActivitySourceAutomation = "automation"
+)
+
+// TaskActivity is one entry in a task's audit trail. It is written in the
+// same transaction as the change it describes and outlives the task itself.
+type TaskActivity struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	TaskID    uint          `json:"task_id" gorm:"not null;index"`
+	BoardID   uint          `json:"board_id" gorm:"not null;index"`
+	ActorID   *uint         `json:"actor_id"` // nil for changes made by the system
+	Action    string        `json:"action" gorm:"not null"`
+	Source    string        `json:"source" gorm:"not null;default:'rest'"`
+	Changes   []FieldChange `json:"changes" gorm:"serializer:json"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	// Relationships
+	Actor *User `json:"-" gorm:"foreignKey:ActorID"`
+}
+
+// FieldChange records the old and new value of a single task field. Old is
+// nil on creation and New is nil on deletion.
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+type TaskActivityResponse struct {
+	ID          uint          `json:"id"`
+	TaskID      uint          `json:"task_id"`
+	BoardID     uint          `json:"board_id"`
+	ActorID     *uint         `json:"actor_id"`
+	ActorName   string        `json:"actor_name"`
+	ActorAvatar string        `json:"actor_avatar"`
+	Action      string        `json:"action"`
+	Source      string        `json:"source"`
+	Changes     []FieldChange `json:"changes"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// TaskAssignee assigns a user to a task. A task may have several assignees;
+// Task.AssigneeID mirrors the first one for older clients.
+type TaskAssignee struct {
+	TaskID uint `json:"task_id" gorm:"primaryKey"`
+	UserID uint `json:"user_id" gorm:"primaryKey;index"`
+}
+
+// TaskWatcher subscribes a board member to every change of a task without
+// assigning it to them.
+type TaskWatcher struct {
+	TaskID uint `json:"task_id" gorm:"primaryKey"`
+	UserID uint `json:"user_id" gorm:"primaryKey;index"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ChecklistItem is a lightweight to-do entry on a task.
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"task_id" gorm:"not null;index"`
+	Title     string    `json:"title" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"not null;default:false"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TaskProgress rolls up a task's checklist and its subtasks. A subtask counts
+// as done once it sits in the board's last column.
+type TaskProgress struct {
+	ChecklistDone  int64 `json:"checklist_done"`
+	ChecklistTotal int64 `json:"checklist_total"`
+	SubtasksDone   int64 `json:"subtasks_done"`
+	SubtasksTotal  int64 `json:"subtasks_total"`
+}
+
+type CreateChecklistItemRequest struct {
+	Title string `json:"title" binding:"required,min=1"`
+}
+
+// UpdateChecklistItemRequest renames or checks off an item. Omitted fields
+// are left unchanged.
+type UpdateChecklistItemRequest struct {
+	Title *string `json:"title" binding:"omitempty,min=1"`
+	Done  *bool   `json:"done"`
+}
+
+// ReorderChecklistRequest lists every item of a checklist in its new order.
+type ReorderChecklistRequest struct {
+	ItemIDs []uint `json:"item_ids" binding:"required,min=1"`
+}
+
+// SetTaskParentRequest turns a task into a subtask of ParentID, or into a
+// top-level task when ParentID is null.
+type SetTaskParentRequest struct {
+	ParentID *uint `json:"parent_id"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// TaskComment is a discussion entry on a task. Mentions holds the IDs of the
+// board members the content @mentions.
+type TaskComment struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TaskID    uint       `json:"task_id" gorm:"not null;index"`
+	BoardID   uint       `json:"board_id" gorm:"not null;index"`
+	UserID    uint       `json:"user_id" gorm:"not null"`
+	Content   string     `json:"content" gorm:"not null"`
+	Mentions  []uint     `json:"mentions" gorm:"serializer:json"`
+	EditedAt  *time.Time `json:"edited_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TaskCommentRevision keeps the content a comment had before an edit.
+type TaskCommentRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CommentID uint      `json:"comment_id" gorm:"not null;index"`
+	Content   string    `json:"content" gorm:"not null"`
+	EditedBy  uint      `json:"edited_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"` // when the content was replaced
+}
+
+type TaskCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1"`
+}
+
+type CommentMention struct {
+	UserID uint   `json:"user_id"`
+	Name   string `json:"name"`
+}
+
+type TaskCommentResponse struct {
+	ID        uint             `json:"id"`
+	TaskID    uint             `json:"task_id"`
+	BoardID   uint             `json:"board_id"`
+	UserID    uint             `json:"user_id"`
+	UserName  string           `json:"user_name"`
+	Avatar    string           `json:"avatar"`
+	Content   string           `json:"content"`
+	Mentions  []CommentMention `json:"mentions"`
+	EditedAt  *time.Time       `json:"edited_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
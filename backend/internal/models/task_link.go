@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Task link types
+const (
+	TaskLinkBlocks     = "blocks"
+	TaskLinkRelatesTo  = "relates_to"
+	TaskLinkDuplicates = "duplicates"
+)
+
+// TaskLink is a typed, directed relation between two tasks on the same
+// board. For blocks links the source must be finished before the target.
+type TaskLink struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BoardID   uint      `json:"board_id" gorm:"not null;index"`
+	SourceID  uint      `json:"source_id" gorm:"not null;uniqueIndex:idx_task_links_source_target_type"`
+	TargetID  uint      `json:"target_id" gorm:"not null;index;uniqueIndex:idx_task_links_source_target_type"`
+	Type      string    `json:"type" gorm:"not null;uniqueIndex:idx_task_links_source_target_type"` // blocks, relates_to, duplicates
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateTaskLinkRequest links the task in the URL to TargetID. blocked_by is
+// accepted as the reverse of blocks and stored as a blocks link.
+type CreateTaskLinkRequest struct {
+	TargetID uint   `json:"target_id" binding:"required"`
+	Type     string `json:"type" binding:"required,oneof=blocks blocked_by relates_to duplicates"`
+}
+
+type DependencyGraphNode struct {
+	ID      uint   `json:"id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Blocked bool   `json:"blocked"` // has a blocker outside the done column
+}
+
+type DependencyGraphEdge struct {
+	ID     uint   `json:"id"`
+	Source uint   `json:"source"`
+	Target uint   `json:"target"`
+	Type   string `json:"type"`
+}
+
+type DependencyGraph struct {
+	Nodes []DependencyGraphNode `json:"nodes"`
+	Edges []DependencyGraphEdge `json:"edges"`
+}
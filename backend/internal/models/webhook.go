@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEventAll subscribes a webhook to every board event.
+const WebhookEventAll = "*"
+
+// WebhookEventPing is sent by the ping endpoint to test a webhook.
+const WebhookEventPing = "ping"
+
+// WebhookEvents lists the board events webhooks can subscribe to. They are the
+// events the board WebSocket carries.
+var WebhookEvents = []string{
+	"board_updated", "board_deleted", "board_restored",
+	"member_joined", "member_removed", "member_role_updated",
+	"column_created", "column_updated", "column_deleted", "column_restored", "columns_reordered", "task_statuses_repaired",
+	"task_created", "task_updated", "task_moved", "task_deleted", "task_restored",
+	"task_due_soon", "task_overdue", "task_watchers_updated", "task_checklist_updated",
+	"task_link_created", "task_link_deleted",
+	"task_comment_created", "task_comment_updated", "task_comment_deleted",
+	"label_created", "label_updated", "label_deleted", "labels_merged",
+	"custom_field_created", "custom_field_updated", "custom_field_deleted",
+	"worklog_created", "worklog_updated", "worklog_deleted", "timer_started", "timer_stopped",
+	"recurring_task_created", "recurring_task_updated", "recurring_task_deleted",
+	"automation_created", "automation_updated", "automation_deleted",
+	"chat_message", "chat_message_deleted",
+}
+
+// Webhook posts the board events it subscribes to to URL, signed with Secret.
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BoardID   uint      `json:"board_id" gorm:"not null;index"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url" gorm:"not null"`
+	Secret    string    `json:"-" gorm:"not null"`
+	Events    []string  `json:"events" gorm:"serializer:json"` // event names, or "*" for all
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for a webhook. Pending deliveries are
+// retried with exponential backoff until they succeed or run out of attempts,
+// and the outcome of the last attempt is kept as the delivery log.
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	WebhookID      uint       `json:"webhook_id" gorm:"not null;index"`
+	BoardID        uint       `json:"board_id" gorm:"not null;index"`
+	Event          string     `json:"event" gorm:"not null"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"not null;index"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index"` // nil once finished
+	ResponseStatus int        `json:"response_status"`
+	ResponseBody   string     `json:"response_body" gorm:"type:text"` // truncated
+	Error          string     `json:"error,omitempty"`
+	DurationMs     int64      `json:"duration_ms"`
+	RedeliveryOf   *uint      `json:"redelivery_of"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookPayload is the JSON body posted for an event.
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	BoardID    uint        `json:"board_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+type CreateWebhookRequest struct {
+	Name    string   `json:"name" binding:"max=100"`
+	URL     string   `json:"url" binding:"required,url"`
+	Secret  string   `json:"secret" binding:"omitempty,min=16"` // generated when empty
+	Events  []string `json:"events" binding:"required,min=1"`
+	Enabled *bool    `json:"enabled"` // defaults to true
+}
+
+// UpdateWebhookRequest changes a webhook. Omitted fields are left unchanged.
+type UpdateWebhookRequest struct {
+	Name    *string  `json:"name" binding:"omitempty,max=100"`
+	URL     *string  `json:"url" binding:"omitempty,url"`
+	Secret  *string  `json:"secret" binding:"omitempty,min=16"`
+	Events  []string `json:"events" binding:"omitempty,min=1"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// WebhookSecretResponse is returned when a webhook is created, the only time
+// its secret is shown.
+type WebhookSecretResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Worklog is time a user spent on a task. A worklog without an end is the
+// user's running timer on that task; Seconds is filled in when it stops.
+type Worklog struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	TaskID    uint       `json:"task_id" gorm:"not null;index;uniqueIndex:idx_worklogs_running,where:ended_at IS NULL"`
+	BoardID   uint       `json:"board_id" gorm:"not null;index"`
+	UserID    uint       `json:"user_id" gorm:"not null;index;uniqueIndex:idx_worklogs_running,where:ended_at IS NULL"` // one running timer per task and user
+	StartedAt time.Time  `json:"started_at" gorm:"not null;index"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Seconds   int64      `json:"seconds" gorm:"not null;default:0"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// WorklogRequest logs time after the fact. The end is given either as
+// ended_at or as a duration from started_at.
+type WorklogRequest struct {
+	StartedAt       time.Time  `json:"started_at" binding:"required"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes *float64   `json:"duration_minutes"` // used when ended_at is omitted
+	Note            string     `json:"note"`
+}
+
+type TimerRequest struct {
+	Note string `json:"note"`
+}
+
+type WorklogResponse struct {
+	Worklog
+	UserName  string  `json:"user_name"`
+	TaskTitle string  `json:"task_title"`
+	Hours     float64 `json:"hours"`
+	Running   bool    `json:"running"`
+}
+
+// TimesheetResponse sums a user's finished worklogs between From and To.
+type TimesheetResponse struct {
+	From       time.Time         `json:"from"`
+	To         time.Time         `json:"to"`
+	TotalHours float64           `json:"total_hours"`
+	Days       []TimesheetDay    `json:"days"`
+	Boards     []TimesheetBoard  `json:"boards"`
+	Tasks      []TimesheetTask   `json:"tasks"`
+	Worklogs   []WorklogResponse `json:"worklogs"`
+}
+
+type TimesheetDay struct {
+	Date  string  `json:"date"` // YYYY-MM-DD in UTC
+	Hours float64 `json:"hours"`
+}
+
+type TimesheetBoard struct {
+	BoardID    uint    `json:"board_id"`
+	BoardTitle string  `json:"board_title"`
+	Hours      float64 `json:"hours"`
+}
+
+type TimesheetTask struct {
+	TaskID    uint    `json:"task_id"`
+	BoardID   uint    `json:"board_id"`
+	TaskTitle string  `json:"task_title"`
+	Hours     float64 `json:"hours"`
+}
@@ -0,0 +1,93 @@
+package oidc
+
+import (
+	"os"
+	"strings"
+)
+
+// Config describes the OpenID Connect provider users can sign in with.
+type Config struct {
+	Name         string // shown on the login button
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // the backend callback registered with the provider
+	Scopes       []string
+
+	// Claims the email address, display name and email verification state are
+	// read from, for providers that deviate from the standard claims
+	EmailClaim         string
+	NameClaim          string
+	EmailVerifiedClaim string
+
+	// RequireVerifiedEmail refuses identities whose email the provider does
+	// not vouch for. Turn it off for providers that never send the claim.
+	RequireVerifiedEmail bool
+}
+
+// ConfigFromEnv reads the provider from the OIDC_* variables. It returns false
+// when OIDC_ISSUER is not set.
+func ConfigFromEnv() (Config, bool) {
+	config := Config{
+		Name:                 envOr("OIDC_PROVIDER_NAME", "SSO"),
+		Issuer:               os.Getenv("OIDC_ISSUER"),
+		ClientID:             os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:         os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:          os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:               strings.Fields(strings.ReplaceAll(envOr("OIDC_SCOPES", "openid email profile"), ",", " ")),
+		EmailClaim:           envOr("OIDC_EMAIL_CLAIM", "email"),
+		NameClaim:            envOr("OIDC_NAME_CLAIM", "name"),
+		EmailVerifiedClaim:   envOr("OIDC_EMAIL_VERIFIED_CLAIM", "email_verified"),
+		RequireVerifiedEmail: !strings.EqualFold(os.Getenv("OIDC_REQUIRE_VERIFIED_EMAIL"), "false"),
+	}
+	if config.Issuer == "" {
+		return config, false
+	}
+	if !containsScope(config.Scopes, "openid") {
+		config.Scopes = append([]string{"openid"}, config.Scopes...)
+	}
+	return config, true
+}
+
+// EnforcedDomains returns the email domains, from SSO_ENFORCED_DOMAINS, whose
+// users must sign in through the provider rather than with a password.
+func EnforcedDomains() []string {
+	var domains []string
+	for _, domain := range strings.Split(os.Getenv("SSO_ENFORCED_DOMAINS"), ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			domains = append(domains, strings.TrimPrefix(domain, "@"))
+		}
+	}
+	return domains
+}
+
+// Enforced reports whether email belongs to a domain that must use SSO.
+func Enforced(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, enforced := range EnforcedDomains() {
+		if domain == enforced {
+			return true
+		}
+	}
+	return false
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,219 @@
+// Package recurrence computes the occurrences of RRULE-style schedules:
+// daily, weekly or monthly, every Interval periods, optionally on given
+// weekdays or days of the month, from a start time until an optional end.
+package recurrence
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	// Embed the zone database; the runtime image ships without one
+	_ "time/tzdata"
+)
+
+// Frequencies
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// maxPeriods bounds the search for the next occurrence, so rules whose days
+// never occur (such as the 31st of every second February) end.
+const maxPeriods = 5000
+
+// weekdays maps RRULE day codes to weekdays.
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a validated schedule. Occurrences fall at Start's time of day in
+// Start's location.
+type Rule struct {
+	Frequency  string
+	Interval   int
+	ByDay      []time.Weekday // weekly rules only; defaults to Start's weekday
+	ByMonthDay []int          // monthly rules only; defaults to Start's day
+	Start      time.Time
+	Until      *time.Time
+}
+
+// New validates a schedule and builds its rule. byDay takes RRULE day codes
+// (MO, TU, ...) and start is moved into the named time zone.
+func New(frequency string, interval int, byDay []string, byMonthDay []int, start time.Time, until *time.Time, timezone string) (*Rule, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.New("unknown timezone " + timezone)
+	}
+
+	rule := &Rule{Frequency: frequency, Interval: interval, Start: start.In(location), Until: until}
+
+	switch frequency {
+	case Daily, Weekly, Monthly:
+	default:
+		return nil, errors.New("frequency must be daily, weekly or monthly")
+	}
+	if interval < 1 || interval > 365 {
+		return nil, errors.New("interval must be between 1 and 365")
+	}
+	if until != nil && until.Before(start) {
+		return nil, errors.New("until must not be before starts_at")
+	}
+
+	if len(byDay) > 0 && frequency != Weekly {
+		return nil, errors.New("by_day only applies to weekly rules")
+	}
+	for _, code := range byDay {
+		day, ok := weekdays[strings.ToUpper(code)]
+		if !ok {
+			return nil, errors.New("by_day takes MO, TU, WE, TH, FR, SA or SU")
+		}
+		if !containsWeekday(rule.ByDay, day) {
+			rule.ByDay = append(rule.ByDay, day)
+		}
+	}
+
+	if len(byMonthDay) > 0 && frequency != Monthly {
+		return nil, errors.New("by_month_day only applies to monthly rules")
+	}
+	for _, day := range byMonthDay {
+		if day < 1 || day > 31 {
+			return nil, errors.New("by_month_day takes days between 1 and 31")
+		}
+		if !containsInt(rule.ByMonthDay, day) {
+			rule.ByMonthDay = append(rule.ByMonthDay, day)
+		}
+	}
+
+	// Weeks run Monday to Sunday, as with the RRULE default WKST=MO
+	sort.Slice(rule.ByDay, func(i, j int) bool { return mondayOffset(rule.ByDay[i]) < mondayOffset(rule.ByDay[j]) })
+	sort.Ints(rule.ByMonthDay)
+	return rule, nil
+}
+
+// Next returns the first occurrence strictly after after, or false when the
+// rule has ended.
+func (r *Rule) Next(after time.Time) (time.Time, bool) {
+	first := 0
+	if after.After(r.Start) {
+		// Skip the periods that lie entirely before after
+		first = r.periodsBetween(r.Start, after) - 1
+		if first < 0 {
+			first = 0
+		}
+		first -= first % r.Interval
+	}
+
+	for period := first; period < first+maxPeriods*r.Interval; period += r.Interval {
+		for _, occurrence := range r.occurrences(period) {
+			if occurrence.Before(r.Start) || !occurrence.After(after) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return occurrence, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Upcoming returns up to n occurrences after after.
+func (r *Rule) Upcoming(after time.Time, n int) []time.Time {
+	upcoming := []time.Time{}
+	for len(upcoming) < n {
+		next, ok := r.Next(after)
+		if !ok {
+			break
+		}
+		upcoming = append(upcoming, next)
+		after = next
+	}
+	return upcoming
+}
+
+// occurrences lists the occurrences in the period-th day, week or month after
+// the one Start falls in, in order.
+func (r *Rule) occurrences(period int) []time.Time {
+	year, month, day := r.Start.Date()
+	hour, min, sec := r.Start.Clock()
+	location := r.Start.Location()
+
+	switch r.Frequency {
+	case Daily:
+		return []time.Time{time.Date(year, month, day+period, hour, min, sec, 0, location)}
+	case Weekly:
+		monday := day - mondayOffset(r.Start.Weekday()) + 7*period
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{r.Start.Weekday()}
+		}
+		occurrences := make([]time.Time, 0, len(days))
+		for _, weekday := range days {
+			occurrences = append(occurrences, time.Date(year, month, monday+mondayOffset(weekday), hour, min, sec, 0, location))
+		}
+		return occurrences
+	default:
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{day}
+		}
+		firstOfMonth := time.Date(year, month+time.Month(period), 1, hour, min, sec, 0, location)
+		occurrences := make([]time.Time, 0, len(days))
+		for _, monthDay := range days {
+			occurrence := firstOfMonth.AddDate(0, 0, monthDay-1)
+			// Months without the day are skipped, as in RRULE
+			if occurrence.Month() == firstOfMonth.Month() {
+				occurrences = append(occurrences, occurrence)
+			}
+		}
+		return occurrences
+	}
+}
+
+// periodsBetween counts the whole days, weeks or months from start to t.
+func (r *Rule) periodsBetween(start, t time.Time) int {
+	t = t.In(start.Location())
+	switch r.Frequency {
+	case Daily:
+		return int(t.Sub(start).Hours() / 24)
+	case Weekly:
+		return int(t.Sub(start).Hours() / (24 * 7))
+	default:
+		return (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+	}
+}
+
+// mondayOffset is the number of days from Monday to day.
+func mondayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func mustRule(t *testing.T, frequency string, interval int, byDay []string, byMonthDay []int, start time.Time, until *time.Time, timezone string) *Rule {
+	t.Helper()
+	rule, err := New(frequency, interval, byDay, byMonthDay, start, until, timezone)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return rule
+}
+
+func utc(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ny := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, newYork)
+	}
+	until := utc(2026, time.January, 3, 9, 0)
+
+	tests := []struct {
+		name       string
+		frequency  string
+		interval   int
+		byDay      []string
+		byMonthDay []int
+		start      time.Time
+		until      *time.Time
+		timezone   string
+		after      time.Time
+		want       time.Time
+		wantOK     bool
+	}{
+		{
+			name:      "start is the first occurrence",
+			frequency: Daily, interval: 1,
+			start:  utc(2026, time.January, 1, 9, 0),
+			after:  utc(2026, time.January, 1, 8, 0),
+			want:   utc(2026, time.January, 1, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "occurrences are strictly after after",
+			frequency: Daily, interval: 1,
+			start:  utc(2026, time.January, 1, 9, 0),
+			after:  utc(2026, time.January, 1, 9, 0),
+			want:   utc(2026, time.January, 2, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "daily interval skips to the next matching period",
+			frequency: Daily, interval: 3,
+			start:  utc(2026, time.January, 1, 9, 0),
+			after:  utc(2026, time.January, 10, 12, 0),
+			want:   utc(2026, time.January, 13, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "daily interval keeps an occurrence later the same day",
+			frequency: Daily, interval: 3,
+			start:  utc(2026, time.January, 1, 9, 0),
+			after:  utc(2026, time.January, 10, 8, 59),
+			want:   utc(2026, time.January, 10, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "period skipping far after the start",
+			frequency: Daily, interval: 7,
+			start:  utc(2026, time.January, 1, 9, 0),
+			after:  utc(2030, time.June, 1, 0, 0),
+			want:   utc(2030, time.June, 6, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weekly defaults to the start's weekday",
+			frequency: Weekly, interval: 2,
+			start:  utc(2026, time.January, 1, 10, 0), // Thursday
+			after:  utc(2026, time.January, 2, 0, 0),
+			want:   utc(2026, time.January, 15, 10, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weekly skips days of the start week before the start",
+			frequency: Weekly, interval: 2, byDay: []string{"MO", "SU"},
+			start:  utc(2026, time.January, 4, 10, 0), // Sunday
+			after:  utc(2026, time.January, 3, 0, 0),
+			want:   utc(2026, time.January, 4, 10, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weeks start on Monday",
+			frequency: Weekly, interval: 2, byDay: []string{"SU", "MO"},
+			start:  utc(2026, time.January, 4, 10, 0), // Sunday, last day of its week
+			after:  utc(2026, time.January, 4, 10, 0),
+			want:   utc(2026, time.January, 12, 10, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weekly keeps the later day of the same week",
+			frequency: Weekly, interval: 2, byDay: []string{"MO", "SU"},
+			start:  utc(2026, time.January, 4, 10, 0),
+			after:  utc(2026, time.January, 12, 10, 0),
+			want:   utc(2026, time.January, 18, 10, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weekly interval skips the week in between",
+			frequency: Weekly, interval: 2, byDay: []string{"MO", "SU"},
+			start:  utc(2026, time.January, 4, 10, 0),
+			after:  utc(2026, time.January, 18, 10, 0),
+			want:   utc(2026, time.January, 26, 10, 0),
+			wantOK: true,
+		},
+		{
+			name:      "monthly skips months without the start's day",
+			frequency: Monthly, interval: 1,
+			start:  utc(2026, time.January, 31, 8, 0),
+			after:  utc(2026, time.January, 31, 8, 0),
+			want:   utc(2026, time.March, 31, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:      "monthly skips the 29th in a short February",
+			frequency: Monthly, interval: 1, byMonthDay: []int{29},
+			start:  utc(2026, time.January, 15, 8, 0),
+			after:  utc(2026, time.January, 29, 8, 0),
+			want:   utc(2026, time.March, 29, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:      "monthly keeps the 29th in a leap February",
+			frequency: Monthly, interval: 1, byMonthDay: []int{29},
+			start:  utc(2028, time.January, 15, 8, 0),
+			after:  utc(2028, time.January, 29, 8, 0),
+			want:   utc(2028, time.February, 29, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:      "monthly skips to the 31st far after the start",
+			frequency: Monthly, interval: 1,
+			start:  utc(2026, time.January, 31, 8, 0),
+			after:  utc(2026, time.August, 1, 0, 0),
+			want:   utc(2026, time.August, 31, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:      "monthly interval skips whole months",
+			frequency: Monthly, interval: 3, byMonthDay: []int{30},
+			start:  utc(2026, time.January, 1, 8, 0),
+			after:  utc(2026, time.February, 1, 0, 0),
+			want:   utc(2026, time.April, 30, 8, 0),
+			wantOK: true,
+		},
+		{
+			name:      "rule never occurring ends",
+			frequency: Monthly, interval: 12, byMonthDay: []int{31},
+			start:  utc(2026, time.February, 1, 8, 0),
+			after:  utc(2026, time.February, 1, 0, 0),
+			wantOK: false,
+		},
+		{
+			name:      "daily keeps its time of day after DST ends",
+			frequency: Daily, interval: 1, timezone: "America/New_York",
+			start:  ny(time.October, 30, 9, 0),
+			after:  ny(time.October, 31, 9, 0),
+			want:   ny(time.November, 1, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "period skipping across the end of DST",
+			frequency: Daily, interval: 1, timezone: "America/New_York",
+			start:  ny(time.October, 30, 9, 0),
+			after:  ny(time.November, 2, 8, 30),
+			want:   ny(time.November, 2, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "period skipping across the start of DST",
+			frequency: Daily, interval: 1, timezone: "America/New_York",
+			start:  ny(time.March, 6, 9, 0),
+			after:  ny(time.March, 9, 9, 30),
+			want:   ny(time.March, 10, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "weekly keeps its time of day across DST",
+			frequency: Weekly, interval: 1, timezone: "America/New_York",
+			start:  ny(time.March, 2, 9, 0),
+			after:  ny(time.March, 9, 8, 0),
+			want:   ny(time.March, 9, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "occurrence on until is kept",
+			frequency: Daily, interval: 1,
+			start:  utc(2026, time.January, 1, 9, 0),
+			until:  &until,
+			after:  utc(2026, time.January, 2, 9, 0),
+			want:   utc(2026, time.January, 3, 9, 0),
+			wantOK: true,
+		},
+		{
+			name:      "occurrences after until end the rule",
+			frequency: Daily, interval: 1,
+			start:  utc(2026, time.January, 1, 9, 0),
+			until:  &until,
+			after:  utc(2026, time.January, 3, 9, 0),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := mustRule(t, tt.frequency, tt.interval, tt.byDay, tt.byMonthDay, tt.start, tt.until, tt.timezone)
+			got, ok := rule.Next(tt.after)
+			if ok != tt.wantOK {
+				t.Fatalf("Next(%v) ok = %v, want %v (got %v)", tt.after, ok, tt.wantOK, got)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	until := utc(2026, time.April, 1, 0, 0)
+	rule := mustRule(t, Monthly, 1, nil, []int{30, 29}, utc(2026, time.January, 15, 8, 0), &until, "")
+
+	want := []time.Time{
+		utc(2026, time.January, 29, 8, 0),
+		utc(2026, time.January, 30, 8, 0),
+		utc(2026, time.March, 29, 8, 0),
+		utc(2026, time.March, 30, 8, 0),
+	}
+	got := rule.Upcoming(rule.Start, 10)
+	if len(got) != len(want) {
+		t.Fatalf("Upcoming = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Upcoming[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	start := utc(2026, time.January, 1, 9, 0)
+	before := start.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		frequency  string
+		interval   int
+		byDay      []string
+		byMonthDay []int
+		until      *time.Time
+		timezone   string
+	}{
+		{name: "unknown frequency", frequency: "yearly", interval: 1},
+		{name: "zero interval", frequency: Daily, interval: 0},
+		{name: "interval too large", frequency: Daily, interval: 366},
+		{name: "until before start", frequency: Daily, interval: 1, until: &before},
+		{name: "by_day on a monthly rule", frequency: Monthly, interval: 1, byDay: []string{"MO"}},
+		{name: "unknown day code", frequency: Weekly, interval: 1, byDay: []string{"XX"}},
+		{name: "by_month_day on a weekly rule", frequency: Weekly, interval: 1, byMonthDay: []int{1}},
+		{name: "day of month out of range", frequency: Monthly, interval: 1, byMonthDay: []int{32}},
+		{name: "unknown timezone", frequency: Daily, interval: 1, timezone: "Mars/Olympus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.frequency, tt.interval, tt.byDay, tt.byMonthDay, start, tt.until, tt.timezone); err == nil {
+				t.Error("New succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package search
+
+import (
+	"strings"
+)
+
+// snippetRadius is how many characters of context the LIKE fallback keeps
+// around the first match.
+const snippetRadius = 60
+
+// likeDialect matches every term with LIKE. It needs no index and is only
+// used when SQLite lacks FTS5.
+type likeDialect struct{}
+
+func (likeDialect) match(src source, text string) (string, string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, term := range Terms(text) {
+		conditions = append(conditions, "LOWER("+concat(src)+") LIKE ?")
+		args = append(args, "%"+strings.ToLower(term)+"%")
+	}
+	return "", strings.Join(conditions, " AND "), args
+}
+
+// snippet selects the whole text; finish cuts the excerpt out of it.
+func (likeDialect) snippet(src source, text string) (string, []interface{}) {
+	return concat(src), nil
+}
+
+func (likeDialect) score(src source, text string) (string, []interface{}) {
+	return "0", nil
+}
+
+func (likeDialect) finish(snippet, text string) string {
+	runes := []rune(snippet)
+	lower := []rune(strings.ToLower(snippet))
+
+	terms := Terms(strings.ToLower(text))
+	start, end := -1, -1
+	for _, term := range terms {
+		if i := strings.Index(string(lower), term); i >= 0 {
+			pos := len([]rune(string(lower)[:i]))
+			if start == -1 || pos < start {
+				start, end = pos, pos+len([]rune(term))
+			}
+		}
+	}
+	if start == -1 {
+		if len(runes) > 2*snippetRadius {
+			return string(runes[:2*snippetRadius]) + "…"
+		}
+		return snippet
+	}
+
+	from, to := start-snippetRadius, end+snippetRadius
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(runes) {
+		to, suffix = len(runes), ""
+	}
+	return prefix + string(runes[from:start]) + markStart + string(runes[start:end]) + markEnd + string(runes[end:to]) + suffix
+}
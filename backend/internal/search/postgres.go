@@ -0,0 +1,50 @@
+package search
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// textSearchConfig is the Postgres text search configuration used for both
+// indexing and querying.
+const textSearchConfig = "english"
+
+// document is the tsvector expression of a source. The GIN index is built on
+// exactly this expression so queries can use it.
+func document(src source) string {
+	return fmt.Sprintf("to_tsvector('%s', %s)", textSearchConfig, concat(src))
+}
+
+// setupPostgres creates a GIN expression index per source.
+func setupPostgres(db *gorm.DB) error {
+	for _, src := range sources {
+		statement := fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search ON %s USING GIN ((%s))", src.table, src.table, document(src))
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) match(src source, text string) (string, string, []interface{}) {
+	where := fmt.Sprintf("%s @@ plainto_tsquery('%s', ?)", document(src), textSearchConfig)
+	return "", where, []interface{}{text}
+}
+
+func (postgresDialect) snippet(src source, text string) (string, []interface{}) {
+	expr := fmt.Sprintf("ts_headline('%s', %s, plainto_tsquery('%s', ?), 'StartSel=%s, StopSel=%s, MaxWords=24, MinWords=8')",
+		textSearchConfig, concat(src), textSearchConfig, markStart, markEnd)
+	return expr, []interface{}{text}
+}
+
+func (postgresDialect) score(src source, text string) (string, []interface{}) {
+	expr := fmt.Sprintf("ts_rank(%s, plainto_tsquery('%s', ?))", document(src), textSearchConfig)
+	return expr, []interface{}{text}
+}
+
+func (postgresDialect) finish(snippet, text string) string {
+	return snippet
+}
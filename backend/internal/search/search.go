@@ -0,0 +1,263 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"kanban-backend/internal/logger"
+
+	"gorm.io/gorm"
+)
+
+// Result types
+const (
+	TypeTask           = "task"
+	TypeTaskComment    = "task_comment"
+	TypeChatMessage    = "chat_message"
+	TypePrivateMessage = "private_message"
+)
+
+// BoardTypes are the result types that belong to a board.
+var BoardTypes = []string{TypeTask, TypeTaskComment, TypeChatMessage}
+
+// IsType reports whether t is a known result type.
+func IsType(t string) bool {
+	return t == TypePrivateMessage || containsString(BoardTypes, t)
+}
+
+// Query describes one search request. BoardIDs limits board content to the
+// boards the caller belongs to; private messages are limited to UserID.
+type Query struct {
+	Text     string
+	UserID   uint
+	BoardIDs []uint
+	Types    []string // empty means every type
+	Limit    int
+	Offset   int
+}
+
+type Result struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	BoardID   *uint     `json:"board_id,omitempty"`
+	TaskID    *uint     `json:"task_id,omitempty"`
+	UserID    uint      `json:"user_id"` // author of the matched content
+	Title     string    `json:"title,omitempty"`
+	Snippet   string    `json:"snippet"` // HTML-escaped, matched terms wrapped in <mark></mark>
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Response struct {
+	Results []Result         `json:"results"`
+	Facets  map[string]int64 `json:"facets"` // matches per type
+	Total   int64            `json:"total"`
+}
+
+// Searcher runs full-text queries against tasks, task comments, board chat and
+// private messages.
+type Searcher interface {
+	Search(q Query) (*Response, error)
+}
+
+// New returns the searcher for the database in use: Postgres text search,
+// SQLite FTS5, or plain LIKE matching when SQLite was built without FTS5.
+func New(db *gorm.DB) Searcher {
+	if db.Dialector.Name() == "postgres" {
+		if err := setupPostgres(db); err != nil {
+			logger.Log.Errorf("Failed to create search indexes: %v", err)
+		}
+		return &engine{db: db, dialect: postgresDialect{}}
+	}
+
+	if err := setupSQLiteFTS(db); err != nil {
+		logger.Log.Warnf("SQLite FTS5 unavailable, falling back to LIKE search (build with -tags sqlite_fts5): %v", err)
+		if err := DropSQLiteTriggers(db); err != nil {
+			logger.Log.Errorf("Failed to drop search triggers: %v", err)
+		}
+		return &engine{db: db, dialect: likeDialect{}}
+	}
+	return &engine{db: db, dialect: sqliteDialect{}}
+}
+
+// source is a searchable table and the access rule for its rows.
+type source struct {
+	typ     string
+	table   string
+	columns []string // text columns that are searched
+	joins   string
+	fields  string // id, board_id, task_id, user_id, title, created_at
+	scope   func(q Query) (string, []interface{})
+}
+
+var sources = []source{
+	{
+		typ:     TypeTask,
+		table:   "tasks",
+		columns: []string{"title", "description"},
+		fields:  "tasks.id, tasks.board_id, tasks.id AS task_id, tasks.created_by AS user_id, tasks.title, tasks.created_at",
+		scope: func(q Query) (string, []interface{}) {
+			return "tasks.board_id IN ? AND tasks.deleted_at IS NULL", []interface{}{q.BoardIDs}
+		},
+	},
+	{
+		typ:     TypeTaskComment,
+		table:   "task_comments",
+		columns: []string{"content"},
+		joins:   "JOIN tasks ON tasks.id = task_comments.task_id AND tasks.deleted_at IS NULL",
+		fields:  "task_comments.id, task_comments.board_id, task_comments.task_id, task_comments.user_id, tasks.title, task_comments.created_at",
+		scope: func(q Query) (string, []interface{}) {
+			return "task_comments.board_id IN ?", []interface{}{q.BoardIDs}
+		},
+	},
+	{
+		typ:     TypeChatMessage,
+		table:   "chat_messages",
+		columns: []string{"content"},
+		fields:  "chat_messages.id, chat_messages.board_id, NULL AS task_id, chat_messages.user_id, '' AS title, chat_messages.created_at",
+		scope: func(q Query) (string, []interface{}) {
+			return "chat_messages.board_id IN ?", []interface{}{q.BoardIDs}
+		},
+	},
+	{
+		typ:     TypePrivateMessage,
+		table:   "private_messages",
+		columns: []string{"content"},
+		fields:  "private_messages.id, NULL AS board_id, NULL AS task_id, private_messages.sender_id AS user_id, '' AS title, private_messages.created_at",
+		scope: func(q Query) (string, []interface{}) {
+			return "(private_messages.sender_id = ? OR private_messages.recipient_id = ?)", []interface{}{q.UserID, q.UserID}
+		},
+	},
+}
+
+// Dialects wrap matches in these private-use characters instead of <mark>
+// tags, so highlight can escape the text first and then add the tags.
+const (
+	markStart = "\ue000"
+	markEnd   = "\ue001"
+)
+
+var markReplacer = strings.NewReplacer(markStart, "<mark>", markEnd, "</mark>")
+
+// highlight escapes a snippet for HTML and turns its match markers into
+// <mark> tags.
+func highlight(snippet string) string {
+	return markReplacer.Replace(html.EscapeString(snippet))
+}
+
+// dialect supplies the database specific parts of a full-text query.
+type dialect interface {
+	// match returns the join and condition selecting rows of src that match text.
+	match(src source, text string) (joins, where string, args []interface{})
+	// snippet returns an expression for the excerpt, with matches between
+	// markStart and markEnd.
+	snippet(src source, text string) (expr string, args []interface{})
+	// score returns an expression ranking matches, higher is better.
+	score(src source, text string) (expr string, args []interface{})
+	// finish post-processes a snippet read from the database.
+	finish(snippet, text string) string
+}
+
+type engine struct {
+	db      *gorm.DB
+	dialect dialect
+}
+
+func (e *engine) Search(q Query) (*Response, error) {
+	response := &Response{Results: []Result{}, Facets: map[string]int64{}}
+
+	for _, src := range sources {
+		if len(q.Types) > 0 && !containsString(q.Types, src.typ) {
+			continue
+		}
+
+		joins, where, matchArgs := e.dialect.match(src, q.Text)
+		scope, scopeArgs := src.scope(q)
+
+		query := func() *gorm.DB {
+			tx := e.db.Table(src.table)
+			if joins != "" {
+				tx = tx.Joins(joins)
+			}
+			if src.joins != "" {
+				tx = tx.Joins(src.joins)
+			}
+			return tx.Where(where, matchArgs...).Where(scope, scopeArgs...)
+		}
+
+		var count int64
+		if err := query().Count(&count).Error; err != nil {
+			return nil, err
+		}
+		response.Facets[src.typ] = count
+		response.Total += count
+		if count == 0 {
+			continue
+		}
+
+		snippet, snippetArgs := e.dialect.snippet(src, q.Text)
+		score, scoreArgs := e.dialect.score(src, q.Text)
+		args := append(append([]interface{}{}, snippetArgs...), scoreArgs...)
+
+		var rows []Result
+		if err := query().
+			Select(src.fields+", "+snippet+" AS snippet, "+score+" AS score", args...).
+			Order("score desc").
+			Limit(q.Offset + q.Limit).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			row.Type = src.typ
+			row.Snippet = highlight(e.dialect.finish(row.Snippet, q.Text))
+			response.Results = append(response.Results, row)
+		}
+	}
+
+	sort.SliceStable(response.Results, func(i, j int) bool {
+		a, b := response.Results[i], response.Results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.CreatedAt.After(b.CreatedAt)
+	})
+
+	if q.Offset >= len(response.Results) {
+		response.Results = []Result{}
+	} else {
+		response.Results = response.Results[q.Offset:]
+		if len(response.Results) > q.Limit {
+			response.Results = response.Results[:q.Limit]
+		}
+	}
+
+	return response, nil
+}
+
+// Terms splits text into the words that are searched for.
+func Terms(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// concat joins the text columns of src into one SQL expression.
+func concat(src source) string {
+	parts := make([]string, len(src.columns))
+	for i, column := range src.columns {
+		parts[i] = "COALESCE(" + src.table + "." + column + ", '')"
+	}
+	return strings.Join(parts, " || ' ' || ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
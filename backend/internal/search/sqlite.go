@@ -0,0 +1,86 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// setupSQLiteFTS creates an external-content FTS5 table for every source,
+// kept in sync by triggers. The triggers are dropped before every migration
+// (see DropSQLiteTriggers), so the index is rebuilt to pick up rows written
+// while they were missing.
+func setupSQLiteFTS(db *gorm.DB) error {
+	for _, src := range sources {
+		fts := src.table + "_fts"
+		columns := strings.Join(src.columns, ", ")
+		newValues := "new." + strings.Join(src.columns, ", new.")
+		oldValues := "old." + strings.Join(src.columns, ", old.")
+
+		statements := []string{
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(%s, content='%s', content_rowid='id')", fts, columns, src.table),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_ai AFTER INSERT ON %s BEGIN INSERT INTO %s(rowid, %s) VALUES (new.id, %s); END",
+				fts, src.table, fts, columns, newValues),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_ad AFTER DELETE ON %s BEGIN INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s); END",
+				fts, src.table, fts, fts, columns, oldValues),
+			fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_au AFTER UPDATE ON %s BEGIN INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.id, %s); INSERT INTO %s(rowid, %s) VALUES (new.id, %s); END",
+				fts, src.table, fts, fts, columns, oldValues, fts, columns, newValues),
+			fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", fts, fts),
+		}
+
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DropSQLiteTriggers removes the index sync triggers. A binary built without
+// FTS5 cannot migrate or write to tables that still carry them.
+func DropSQLiteTriggers(db *gorm.DB) error {
+	for _, src := range sources {
+		for _, suffix := range []string{"_ai", "_ad", "_au"} {
+			if err := db.Exec("DROP TRIGGER IF EXISTS " + src.table + "_fts" + suffix).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type sqliteDialect struct{}
+
+// ftsQuery quotes every term so user input cannot use FTS5 syntax. The last
+// term matches as a prefix so results show up while typing.
+func ftsQuery(text string) string {
+	terms := Terms(text)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	if len(terms) > 0 {
+		terms[len(terms)-1] += "*"
+	}
+	return strings.Join(terms, " ")
+}
+
+func (sqliteDialect) match(src source, text string) (string, string, []interface{}) {
+	fts := src.table + "_fts"
+	joins := fmt.Sprintf("JOIN %s ON %s.rowid = %s.id", fts, fts, src.table)
+	return joins, fts + " MATCH ?", []interface{}{ftsQuery(text)}
+}
+
+func (sqliteDialect) snippet(src source, text string) (string, []interface{}) {
+	return fmt.Sprintf("snippet(%s_fts, -1, '%s', '%s', '…', 16)", src.table, markStart, markEnd), nil
+}
+
+// score negates bm25, where lower means more relevant.
+func (sqliteDialect) score(src source, text string) (string, []interface{}) {
+	return fmt.Sprintf("-bm25(%s_fts)", src.table), nil
+}
+
+func (sqliteDialect) finish(snippet, text string) string {
+	return snippet
+}
@@ -20,8 +20,12 @@ type Hub struct {
 	broadcast  chan []byte
 	register   chan *Client
 	unregister chan *Client
+	listeners  []BoardEventListener
 }
 
+// BoardEventListener is called with every event broadcast to a board.
+type BoardEventListener func(boardID uint, messageType string, data interface{})
+
 type Client struct {
 	hub     *Hub
 	conn    *websocket.Conn
@@ -163,6 +167,16 @@ func (h *Hub) BroadcastToBoard(boardID uint, messageType string, data interface{
 	if jsonData, err := json.Marshal(message); err == nil {
 		h.broadcast <- jsonData
 	}
+
+	for _, listener := range h.listeners {
+		listener(boardID, messageType, data)
+	}
+}
+
+// AddBoardEventListener registers listener for every board event. Listeners
+// must be added before the hub starts broadcasting.
+func (h *Hub) AddBoardEventListener(listener BoardEventListener) {
+	h.listeners = append(h.listeners, listener)
 }
 
 // IsUserOnline checks if a user is currently connected to a specific board